@@ -0,0 +1,228 @@
+package counter
+
+import "gnlp"
+import "math"
+import "sync"
+
+const shardCount = 32
+
+type shard struct {
+	sync.Mutex
+	values map[string]float64
+}
+
+// A counter that's safe to update from several goroutines at once.
+// Keys are spread over shards, each with its own lock, so concurrent
+// updates to different keys rarely contend. Operations touching every
+// value (including the default) lock all of the shards.
+type Concurrent struct {
+	shards [shardCount]shard
+	// default value for missing items, guarded by every shard's lock
+	base float64
+}
+
+func NewConcurrent(base float64) *Concurrent {
+	c := &Concurrent{base: base}
+	for i := range c.shards {
+		c.shards[i].values = make(map[string]float64)
+	}
+
+	return c
+}
+
+// FNV-1a
+func (c *Concurrent) shard(k string) *shard {
+	h := uint32(2166136261)
+	for i := 0; i < len(k); i++ {
+		h ^= uint32(k[i])
+		h *= 16777619
+	}
+
+	return &c.shards[h%shardCount]
+}
+
+func (c *Concurrent) lockAll() {
+	for i := range c.shards {
+		c.shards[i].Lock()
+	}
+}
+
+func (c *Concurrent) unlockAll() {
+	for i := range c.shards {
+		c.shards[i].Unlock()
+	}
+}
+
+// Return a value for a key (falling back to the default)
+func (c *Concurrent) Get(k string) float64 {
+	s := c.shard(k)
+	s.Lock()
+	defer s.Unlock()
+
+	if v, ok := s.values[k]; ok {
+		return v
+	}
+	return c.base
+}
+
+func (c *Concurrent) set(s *shard, k string, v float64) {
+	if v == c.base {
+		s.values[k] = 0, false
+		return
+	}
+
+	s.values[k] = v
+}
+
+// Set a value for a key
+func (c *Concurrent) Set(k string, v float64) {
+	s := c.shard(k)
+	s.Lock()
+	defer s.Unlock()
+
+	c.set(s, k, v)
+}
+
+// Add v to a key's value
+func (c *Concurrent) IncrBy(k string, v float64) {
+	s := c.shard(k)
+	s.Lock()
+	defer s.Unlock()
+
+	old, ok := s.values[k]
+	if !ok {
+		old = c.base
+	}
+
+	c.set(s, k, old+v)
+}
+
+// Increment a value
+func (c *Concurrent) Incr(k string) {
+	c.IncrBy(k, 1.0)
+}
+
+// Add o to c, e.g. to merge in a per-goroutine counter
+func (c *Concurrent) Add(o *Counter) {
+	c.lockAll()
+	defer c.unlockAll()
+
+	updates := make(map[string]float64)
+	for i := range c.shards {
+		for k, v := range c.shards[i].values {
+			updates[k] = v + o.Get(k)
+		}
+	}
+
+	for _, k := range o.Keys() {
+		if _, ok := updates[k]; !ok {
+			updates[k] = c.base + o.Get(k)
+		}
+	}
+
+	c.base += o.Base
+	for k, v := range updates {
+		c.set(c.shard(k), k, v)
+	}
+}
+
+// Return a regular counter with the current values of c
+func (c *Concurrent) Snapshot() *Counter {
+	c.lockAll()
+	defer c.unlockAll()
+
+	return c.snapshot()
+}
+
+// Snapshot, with every shard locked
+func (c *Concurrent) snapshot() *Counter {
+	result := New(c.base)
+	for i := range c.shards {
+		for k, v := range c.shards[i].values {
+			result.values[k] = v
+		}
+	}
+
+	return result
+}
+
+func (c *Concurrent) apply(op func(k *string, a float64) float64) {
+	c.base = op(nil, c.base)
+
+	for i := range c.shards {
+		s := &c.shards[i]
+		for k, v := range s.values {
+			c.set(s, k, op(&k, v))
+		}
+	}
+}
+
+// Apply a function to every value in the counter (including the
+// default). c is locked throughout, so op mustn't use it.
+func (c *Concurrent) Apply(op func(k *string, a float64) float64) {
+	c.lockAll()
+	defer c.unlockAll()
+
+	c.apply(op)
+}
+
+// Log every value in the counter (including the default)
+func (c *Concurrent) Log() {
+	c.Apply(func(s *string, f float64) float64 { return math.Log(f) })
+}
+
+// Exponentiate every value in the counter (including the default)
+func (c *Concurrent) Exp() {
+	c.Apply(func(s *string, f float64) float64 { return math.Exp(f) })
+}
+
+// The sum over values (not including the default), with every shard
+// locked
+func (c *Concurrent) sum() float64 {
+	sum := 0.0
+	for i := range c.shards {
+		for _, v := range c.shards[i].values {
+			sum += v
+		}
+	}
+
+	return sum
+}
+
+// Normalize a counter s.t. the sum over values is now 1.0
+func (c *Concurrent) Normalize() {
+	c.lockAll()
+	defer c.unlockAll()
+
+	sum := c.sum()
+	c.apply(func(s *string, a float64) float64 { return a / sum })
+}
+
+// Normalize into a log-distribution
+func (c *Concurrent) LogNormalize() {
+	c.lockAll()
+	defer c.unlockAll()
+
+	logSum := math.Log(c.sum())
+	c.apply(func(s *string, a float64) float64 { return math.Log(a) - logSum })
+}
+
+// ln(sum(exp(v))) over the values in the counter (not including the
+// default)
+func (c *Concurrent) LogSumExp() float64 {
+	c.lockAll()
+	defer c.unlockAll()
+
+	return c.snapshot().LogSumExp()
+}
+
+// Normalize a log-distribution without leaving log space
+func (c *Concurrent) LogRenormalize() {
+	c.lockAll()
+	defer c.unlockAll()
+
+	logSum := c.snapshot().LogSumExp()
+	c.apply(func(s *string, a float64) float64 { return a - logSum })
+}
+
+var _ gnlp.Counter = NewConcurrent(0.0)
@@ -0,0 +1,76 @@
+package counter
+
+import "fmt"
+import "math"
+import "sync"
+import "testing"
+
+func TestConcurrentIncr(t *testing.T) {
+	c := NewConcurrent(0.0)
+	wg := &sync.WaitGroup{}
+
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Incr(fmt.Sprintf("key%d", i%10))
+				c.Get("key0")
+			}
+		}()
+	}
+	wg.Wait()
+
+	snapshot := c.Snapshot()
+	if len(snapshot.Keys()) != 10 || snapshot.Get("key3") != 800 {
+		t.Errorf("Expected 10 keys counted 800 times, got %s", snapshot)
+	}
+}
+
+func TestConcurrentMerge(t *testing.T) {
+	c := NewConcurrent(0.0)
+	wg := &sync.WaitGroup{}
+
+	// Per-goroutine counters merged at the end
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+
+			local := New(0.0)
+			local.Incr("shared")
+			local.Set(fmt.Sprintf("own%d", g), float64(g+1))
+			c.Add(local)
+		}(g)
+	}
+	wg.Wait()
+
+	if c.Get("shared") != 4 || c.Get("own2") != 3 || c.Get("missing") != 0 {
+		t.Errorf("Bad merged counts %s", c.Snapshot())
+	}
+
+	o := New(1.0)
+	o.Set("shared", 2.0)
+	c.Add(o)
+	if c.Get("shared") != 6 || c.Get("own0") != 2 || c.Get("missing") != 1 {
+		t.Errorf("Adding a counter should also add its default, got %s", c.Snapshot())
+	}
+}
+
+func TestConcurrentNormalize(t *testing.T) {
+	c := NewConcurrent(0.0)
+	c.Set("blue", 2.0)
+	c.Incr("red")
+
+	c.Normalize()
+	if c.Get("blue") != 2.0/3.0 {
+		t.Errorf("Blue = %f, expected 2/3", c.Get("blue"))
+	}
+
+	c.Set("blue", 2.0)
+	c.Set("red", 1.0)
+	c.LogNormalize()
+	if math.Abs(c.Get("red")-math.Log(1.0/3.0)) > 1e-12 || !math.IsInf(c.Get("green"), -1) {
+		t.Errorf("Bad log distribution %s", c.Snapshot())
+	}
+}
@@ -0,0 +1,206 @@
+package counter
+
+import "fmt"
+
+// Counts (or probabilities) of events given conditions, e.g. word
+// counts per class for P(word | class). Each condition has its own
+// Counter.
+type Conditional struct {
+	counters map[string]*Counter
+	// default value for missing events and unseen conditions
+	Base float64
+}
+
+func NewConditional(base float64) *Conditional {
+	return &Conditional{make(map[string]*Counter), base}
+}
+
+// The counter for a condition, which is created if it doesn't exist
+func (c *Conditional) Given(cond string) *Counter {
+	dist, ok := c.counters[cond]
+	if !ok {
+		dist = New(c.Base)
+		c.counters[cond] = dist
+	}
+
+	return dist
+}
+
+// Return a value for an event given a condition (falling back to the
+// condition's default, or c.Base for an unseen condition)
+func (c *Conditional) Get(cond, event string) float64 {
+	dist, ok := c.counters[cond]
+	if !ok {
+		return c.Base
+	}
+
+	return dist.Get(event)
+}
+
+// Set a value for an event given a condition
+func (c *Conditional) Set(cond, event string, v float64) {
+	c.Given(cond).Set(event, v)
+}
+
+// Increment the value for an event given a condition
+func (c *Conditional) Incr(cond, event string) {
+	c.Given(cond).Incr(event)
+}
+
+// Return the conditions seen so far
+func (c *Conditional) Conditions() []string {
+	result := make([]string, 0, len(c.counters))
+
+	for cond, _ := range c.counters {
+		result = append(result, cond)
+	}
+
+	return result
+}
+
+// The counter for each condition (shared with c, not copied)
+func (c *Conditional) Counters() map[string]*Counter {
+	return c.counters
+}
+
+func (c *Conditional) String() string {
+	s := "Conditional: {"
+
+	for cond, dist := range c.counters {
+		s += fmt.Sprintf("'%s': %s, ", cond, dist)
+	}
+
+	s += "}"
+
+	return s
+}
+
+// Normalize each condition's counter into P(event | condition)
+func (c *Conditional) Normalize() {
+	for _, dist := range c.counters {
+		dist.Normalize()
+	}
+}
+
+// Normalize each condition's counter into log P(event | condition)
+func (c *Conditional) LogNormalize() {
+	for _, dist := range c.counters {
+		dist.LogNormalize()
+	}
+}
+
+// The sum over each condition's events (not including defaults), e.g.
+// the number of times each condition was seen
+func (c *Conditional) Totals() *Counter {
+	result := New(0.0)
+
+	for cond, dist := range c.counters {
+		result.Set(cond, dist.Sum()-dist.Base)
+	}
+
+	return result
+}
+
+// Every event seen under any condition
+func (c *Conditional) events() []string {
+	seen := make(map[string]bool)
+	result := []string{}
+
+	for _, dist := range c.counters {
+		for _, e := range dist.Keys() {
+			if !seen[e] {
+				seen[e] = true
+				result = append(result, e)
+			}
+		}
+	}
+
+	return result
+}
+
+// Sum out the condition, weighting each condition by prior - given
+// P(event | condition) and P(condition), return P(event). A nil prior
+// weights every condition by 1, e.g. to total raw counts. Probabilities
+// (not logs) are expected.
+func (c *Conditional) Marginalize(prior *Counter) *Counter {
+	weight := func(cond string) float64 {
+		if prior == nil {
+			return 1.0
+		}
+		return prior.Get(cond)
+	}
+
+	base := 0.0
+	for cond, dist := range c.counters {
+		base += weight(cond) * dist.Base
+	}
+
+	result := New(base)
+	for _, e := range c.events() {
+		sum := 0.0
+		for cond, dist := range c.counters {
+			sum += weight(cond) * dist.Get(e)
+		}
+
+		result.Set(e, sum)
+	}
+
+	return result
+}
+
+// Given P(event | condition) and a prior P(condition), return the
+// joint P(condition, event), still indexed by condition. Probabilities
+// (not logs) are expected.
+func (c *Conditional) Joint(prior *Counter) *Conditional {
+	result := NewConditional(c.Base * prior.Base)
+
+	for cond, dist := range c.counters {
+		p := prior.Get(cond)
+		joint := dist.Copy()
+		joint.Apply(func(e *string, v float64) float64 { return p * v })
+
+		result.counters[cond] = joint
+	}
+
+	return result
+}
+
+// Swap conditions and events, e.g. to turn a joint distribution indexed
+// by condition into one indexed by event
+func (c *Conditional) Transpose() *Conditional {
+	result := NewConditional(c.Base)
+
+	for _, e := range c.events() {
+		dist := result.Given(e)
+		for cond, _ := range c.counters {
+			dist.Set(cond, c.Get(cond, e))
+		}
+	}
+
+	return result
+}
+
+// Bayes' rule - given P(event | condition) and a prior P(condition),
+// return P(condition | event), indexed by event. Probabilities (not
+// logs) are expected.
+func (c *Conditional) Invert(prior *Counter) *Conditional {
+	result := c.Joint(prior).Transpose()
+	result.Normalize()
+
+	return result
+}
+
+// Turn a joint distribution over (condition, event) pairs into
+// P(event | condition), where split breaks a joint key into its
+// condition and event
+func FromJoint(joint *Counter, split func(key string) (cond, event string)) *Conditional {
+	result := NewConditional(joint.Base)
+
+	for _, k := range joint.Keys() {
+		cond, event := split(k)
+		result.Set(cond, event, joint.Get(k))
+	}
+
+	result.Normalize()
+	return result
+}
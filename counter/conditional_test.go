@@ -0,0 +1,72 @@
+package counter
+
+import "math"
+import "strings"
+import "testing"
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-12
+}
+
+func TestConditional(t *testing.T) {
+	words := NewConditional(0.0)
+	for _, w := range []string{"good", "good", "fun"} {
+		words.Incr("pos", w)
+	}
+	for _, w := range []string{"bad", "boring", "good", "bad"} {
+		words.Incr("neg", w)
+	}
+
+	if words.Get("neg", "bad") != 2 || words.Get("neg", "fun") != 0 || words.Get("other", "bad") != 0 {
+		t.Fatalf("Bad counts %s", words)
+	}
+
+	totals := words.Totals()
+	if totals.Get("pos") != 3 || totals.Get("neg") != 4 {
+		t.Errorf("Bad totals %s", totals)
+	}
+
+	if counts := words.Marginalize(nil); counts.Get("good") != 3 || counts.Get("bad") != 2 {
+		t.Errorf("Bad marginal counts %s", counts)
+	}
+
+	prior := totals.Copy()
+	prior.Normalize()
+	words.Normalize()
+
+	if !near(words.Get("pos", "good"), 2.0/3.0) {
+		t.Errorf("P(good | pos) = %f", words.Get("pos", "good"))
+	}
+
+	// P(good) = 3/7
+	if p := words.Marginalize(prior); !near(p.Get("good"), 3.0/7.0) || !near(p.Get("fun"), 1.0/7.0) {
+		t.Errorf("Bad marginal %s", p)
+	}
+
+	// P(pos | good) = 2/3
+	posterior := words.Invert(prior)
+	if !near(posterior.Get("good", "pos"), 2.0/3.0) || !near(posterior.Get("fun", "pos"), 1.0) || posterior.Get("fun", "neg") != 0 {
+		t.Errorf("Bad posterior %s", posterior)
+	}
+
+	words.LogNormalize()
+	if !near(words.Get("pos", "good"), math.Log(2.0/3.0)) {
+		t.Errorf("log P(good | pos) = %f", words.Get("pos", "good"))
+	}
+}
+
+func TestFromJoint(t *testing.T) {
+	joint := New(0.0)
+	joint.Set("a x", 0.1)
+	joint.Set("a y", 0.3)
+	joint.Set("b x", 0.6)
+
+	c := FromJoint(joint, func(k string) (string, string) {
+		parts := strings.Split(k, " ")
+		return parts[0], parts[1]
+	})
+
+	if !near(c.Get("a", "y"), 0.75) || !near(c.Get("b", "x"), 1.0) || len(c.Conditions()) != 2 {
+		t.Errorf("Bad conditional %s", c)
+	}
+}
@@ -2,55 +2,43 @@ package counter
 
 import "gnlp"
 import "fmt"
-import "math"
 
+// A counter over string keys - a Keyed whose methods take and return
+// strings
 type Counter struct {
-	values map[string]float64
-	// default value for missing items
-	Base float64
+	*Keyed
 }
 
 func New(base float64) *Counter {
-	return &Counter{make(map[string]float64), base}
+	return &Counter{NewKeyed(base)}
 }
 
 // Return a value for a key (falling back to the default)
 func (c *Counter) Get(k string) float64 {
-	v, ok := c.values[k]
-
-	if ok {
-		return v
-	}
-	return c.Base
+	return c.Keyed.Get(k)
 }
 
 // Set a value for a key
 func (c *Counter) Set(k string, v float64) {
-	if v == c.Base {
-		// remove the key
-		c.values[k] = v, false
-	}
-
-	c.values[k] = v
+	c.Keyed.Set(k, v)
 }
 
 // Increment a value
 func (c *Counter) Incr(k string) {
-	v := c.Get(k)
-	c.Set(k, v+1)
+	c.Keyed.Incr(k)
+}
+
+// Return a new counter with the same values as c
+func (c *Counter) Copy() *Counter {
+	return &Counter{c.Keyed.Copy()}
 }
 
 // Return a list of keys for this counter
 func (c *Counter) Keys() []string {
 	result := make([]string, 0, len(c.values))
 
-	for k, v := range c.values {
-		// Don't track default values
-		if v == c.Base {
-			continue
-		}
-
-		result = append(result, k)
+	for k, _ := range c.values {
+		result = append(result, k.(string))
 	}
 
 	return result
@@ -59,7 +47,7 @@ func (c *Counter) Keys() []string {
 func (c *Counter) String() string {
 	s := "Counter: {"
 
-	for _, key := range c.Keys() {
+	for _, key := range c.SortedKeys() {
 		s += fmt.Sprintf("'%s': %f, ", key, c.Get(key))
 	}
 
@@ -92,134 +80,83 @@ func mergeKeys(a, b []string) <-chan string {
 	return out
 }
 
-// Apply an operation on two counters, returning new counter with keys
-// defined by the keys function
-func operate(a, b *Counter, op func(a, b float64) float64, keys func(a, b []string) <-chan string) *Counter {
-	result := New(op(a.Base, b.Base))
-
-	for k := range keys(a.Keys(), b.Keys()) {
-		result.Set(k, op(a.Get(k), b.Get(k)))
-	}
-
-	return result
-}
-
 // Add a to b, returning a new counter
 func Add(a, b *Counter) *Counter {
-	return operate(a, b, func(a, b float64) float64 { return a + b }, mergeKeys)
+	return &Counter{AddKeyed(a.Keyed, b.Keyed)}
 }
 
 // Subtract b from a, returning a new counter
 func Subtract(a, b *Counter) *Counter {
-	return operate(a, b, func(a, b float64) float64 { return a - b }, mergeKeys)
+	return &Counter{SubtractKeyed(a.Keyed, b.Keyed)}
 }
 
 // Multiply a by b, returning a new counter
 func Multiply(a, b *Counter) *Counter {
-	return operate(a, b, func(a, b float64) float64 { return a * b }, mergeKeys)
+	return &Counter{MultiplyKeyed(a.Keyed, b.Keyed)}
 }
 
 // Divide a by b, returning a new counter
 func Divide(a, b *Counter) *Counter {
-	return operate(a, b, func(a, b float64) float64 { return a / b }, mergeKeys)
-}
-
-// Apply an operation on two counters, updating the first counter with keys
-// defined by the keys function
-func (a *Counter) operate(b *Counter, op func(a, b float64) float64, keys func(a, b []string) <-chan string) {
-	a.Base = op(a.Base, b.Base)
-
-	for k := range keys(a.Keys(), b.Keys()) {
-		a.Set(k, op(a.Get(k), b.Get(k)))
-	}
+	return &Counter{DivideKeyed(a.Keyed, b.Keyed)}
 }
 
 // Add o to c
 func (c *Counter) Add(o *Counter) {
-	c.operate(o, func(a, b float64) float64 { return a + b }, mergeKeys)
+	c.Keyed.Add(o.Keyed)
 }
 
 // Subtract o from c
 func (c *Counter) Subtract(o *Counter) {
-	c.operate(o, func(a, b float64) float64 { return a - b }, mergeKeys)
+	c.Keyed.Subtract(o.Keyed)
 }
 
 // Multiply c by o
 func (c *Counter) Multiply(o *Counter) {
-	c.operate(o, func(a, b float64) float64 { return a * b }, mergeKeys)
+	c.Keyed.Multiply(o.Keyed)
 }
 
 // Divide c by o
 func (c *Counter) Divide(o *Counter) {
-	c.operate(o, func(a, b float64) float64 { return a / b }, mergeKeys)
+	c.Keyed.Divide(o.Keyed)
 }
 
 // Apply a function to every value in the counter (including the
-// default)
+// default, for which the key is nil)
 func (c *Counter) Apply(op func(k *string, a float64) float64) {
-	c.Base = op(nil, c.Base)
-
-	for k, v := range c.values {
-		c.Set(k, op(&k, v))
-	}
-}
-
-// Log every value in the counter (including the default)
-func (c *Counter) Log() {
-	c.Apply(func(s *string, f float64) float64 { return math.Log(f) })
-}
-
-// Exponentiate every value in the counter (including the default)
-func (c *Counter) Exp() {
-	c.Apply(func(s *string, f float64) float64 { return math.Exp(f) })
-}
-
-// Reduce over the values in the counter (not including the default
-// value)
-func (c *Counter) reduce(base float64, op func(a, b float64) float64) float64 {
-	val := base
-
-	for _, v := range c.values {
-		val = op(val, v)
-	}
+	c.Keyed.Apply(func(k interface{}, a float64) float64 {
+		if k == nil {
+			return op(nil, a)
+		}
 
-	return val
+		s := k.(string)
+		return op(&s, a)
+	})
 }
 
+// The key with the largest value and its value, or "" and the default
+// for an empty counter
 func (c *Counter) ArgMax() (string, float64) {
-	var maxKey *string = nil
-	maxVal := 0.0
-
-	c.Apply(func (key *string, val float64) float64 {
-		if val > maxVal || maxKey == nil {
-			maxKey = key
-			maxVal = val
-		}
-
-		return val
-	})
+	k, v := c.Keyed.ArgMax()
+	if k == nil {
+		return "", v
+	}
 
-	return *maxKey, maxVal
+	return k.(string), v
 }
 
+// The sum over values, plus the default
 func (c *Counter) Sum() float64 {
-	return c.reduce(c.Base, func (a, b float64) float64 { return a + b })
+	return c.Base + c.Keyed.Sum()
 }
 
-// Normalize a counter s.t. the sum over values is now 1.0
-func (c *Counter) Normalize() {
-	sum := c.reduce(0.0, func(a, b float64) float64 { return a + b })
-	c.Apply(func(s *string, a float64) float64 { return a / sum })
+// Add o to c in log space, i.e. ln(exp(c) + exp(o))
+func (c *Counter) LogAdd(o *Counter) {
+	c.Keyed.LogAdd(o.Keyed)
 }
 
-// Special case of normalize - normalize a distribution and turn it
-// into a log-distribution (performing the normalization after the
-// xform to maintain precision)
-func (c *Counter) LogNormalize() {
-	sum := c.reduce(0.0, func(a, b float64) float64 { return a + b })
-	logSum := math.Log(sum)
-
-	c.Apply(func(s *string, a float64) float64 { return math.Log(a) - logSum })
+// Add a to b in log space, returning a new counter
+func LogAdd(a, b *Counter) *Counter {
+	return &Counter{LogAddKeyed(a.Keyed, b.Keyed)}
 }
 
 var _ gnlp.Counter = New(0.0)
@@ -1,5 +1,6 @@
 package counter
 
+import "math"
 import "testing"
 
 func TestBasics(t *testing.T) {
@@ -42,3 +43,33 @@ func TestBasics(t *testing.T) {
 		t.Error("Red doesn't have 0.5 probability")
 	}
 }
+
+func TestLogSpace(t *testing.T) {
+	// Large scores would overflow if exponentiated directly
+	scores := New(math.Inf(-1))
+	scores.Set("a", 1000.0)
+	scores.Set("b", 1000.0+math.Log(3.0))
+
+	if lse := scores.LogSumExp(); math.Abs(lse-(1000.0+math.Log(4.0))) > 1e-9 {
+		t.Errorf("LogSumExp = %f", lse)
+	}
+
+	scores.LogRenormalize()
+	if p := math.Exp(scores.Get("b")); math.Abs(p-0.75) > 1e-12 {
+		t.Errorf("P(b) = %f, expected 0.75", p)
+	}
+
+	other := New(math.Inf(-1))
+	other.Set("a", math.Log(0.25))
+	other.Set("c", math.Log(0.5))
+
+	sum := LogAdd(scores, other)
+	if math.Abs(sum.Get("a")-math.Log(0.5)) > 1e-12 || math.Abs(sum.Get("c")-math.Log(0.5)) > 1e-12 || !math.IsInf(sum.Get("d"), -1) {
+		t.Errorf("Bad log sum %s", sum)
+	}
+
+	scores.LogAdd(other)
+	if math.Abs(scores.LogSumExp()-math.Log(1.75)) > 1e-12 {
+		t.Errorf("In-place log sum %s", scores)
+	}
+}
@@ -0,0 +1,156 @@
+package counter
+
+import "math"
+
+// Information theoretic measures over distributions, in nats. Sums run
+// over the keys of the counters involved, with missing keys taking the
+// counter's default value; zero probability terms contribute nothing
+// (0 ln 0 = 0). The Log variants take counters of log probabilities,
+// e.g. after LogNormalize.
+
+// ln(a + b), given ln(a) and ln(b)
+func logAdd(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	if a < b {
+		a, b = b, a
+	}
+
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+func identity(v float64) float64 {
+	return v
+}
+
+// Sum term(ln p(k)) over the keys of p, where toLog turns a value into
+// a log probability
+func sumOne(p *Counter, toLog func(float64) float64, term func(lp float64) float64) float64 {
+	sum := 0.0
+	for _, k := range p.Keys() {
+		sum += term(toLog(p.Get(k)))
+	}
+
+	return sum
+}
+
+// Sum term(ln p(k), ln q(k)) over the keys of p and q
+func sumPairs(p, q *Counter, toLog func(float64) float64, term func(lp, lq float64) float64) float64 {
+	sum := 0.0
+	for k := range mergeKeys(p.Keys(), q.Keys()) {
+		sum += term(toLog(p.Get(k)), toLog(q.Get(k)))
+	}
+
+	return sum
+}
+
+// -p ln(p)
+func entropyTerm(lp float64) float64 {
+	if math.IsInf(lp, -1) {
+		return 0.0
+	}
+	return -math.Exp(lp) * lp
+}
+
+// -p ln(q)
+func crossEntropyTerm(lp, lq float64) float64 {
+	if math.IsInf(lp, -1) {
+		return 0.0
+	}
+	return -math.Exp(lp) * lq
+}
+
+// p ln(p / q)
+func klTerm(lp, lq float64) float64 {
+	if math.IsInf(lp, -1) {
+		return 0.0
+	}
+	return math.Exp(lp) * (lp - lq)
+}
+
+// Half of p's and q's divergence from their mean
+func jsTerm(lp, lq float64) float64 {
+	lm := logAdd(lp, lq) - math.Ln2
+	return 0.5*klTerm(lp, lm) + 0.5*klTerm(lq, lm)
+}
+
+// The entropy of p, H(p) = -sum p ln(p)
+func Entropy(p *Counter) float64 {
+	return sumOne(p, math.Log, entropyTerm)
+}
+
+// The entropy of a log distribution
+func LogEntropy(p *Counter) float64 {
+	return sumOne(p, identity, entropyTerm)
+}
+
+// The cross entropy of q relative to p, H(p, q) = -sum p ln(q)
+func CrossEntropy(p, q *Counter) float64 {
+	return sumPairs(p, q, math.Log, crossEntropyTerm)
+}
+
+// The cross entropy of log distributions
+func LogCrossEntropy(p, q *Counter) float64 {
+	return sumPairs(p, q, identity, crossEntropyTerm)
+}
+
+// The Kullback-Leibler divergence of q from p, sum p ln(p / q). This is
+// +Inf if q is 0 anywhere p isn't.
+func KL(p, q *Counter) float64 {
+	return sumPairs(p, q, math.Log, klTerm)
+}
+
+// The KL divergence of log distributions
+func LogKL(p, q *Counter) float64 {
+	return sumPairs(p, q, identity, klTerm)
+}
+
+// The Jensen-Shannon divergence of p and q - the mean of their KL
+// divergences from (p + q) / 2. It's symmetric and at most ln(2).
+func JS(p, q *Counter) float64 {
+	return sumPairs(p, q, math.Log, jsTerm)
+}
+
+// The JS divergence of log distributions
+func LogJS(p, q *Counter) float64 {
+	return sumPairs(p, q, identity, jsTerm)
+}
+
+// The mutual information between conditions and events, given their
+// joint counts or probabilities (which needn't be normalized). An
+// empty joint, or one with no mass, has no mutual information.
+func MutualInformation(joint *Conditional) float64 {
+	rows := joint.Totals()
+	cols := joint.Marginalize(nil)
+	total := rows.Sum() - rows.Base
+	if total == 0 {
+		return 0.0
+	}
+
+	mi := 0.0
+	for cond, dist := range joint.counters {
+		for _, e := range dist.Keys() {
+			if n := dist.Get(e); n > 0 {
+				mi += n * (math.Log(n) + math.Log(total) - math.Log(rows.Get(cond)) - math.Log(cols.Get(e)))
+			}
+		}
+	}
+
+	return mi / total
+}
+
+// The mutual information given a joint log distribution
+func LogMutualInformation(joint *Conditional) float64 {
+	probs := NewConditional(0.0)
+	for cond, dist := range joint.counters {
+		p := dist.Copy()
+		p.Exp()
+		probs.counters[cond] = p
+	}
+
+	return MutualInformation(probs)
+}
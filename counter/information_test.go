@@ -0,0 +1,99 @@
+package counter
+
+import "math"
+import "testing"
+
+func distribution(base float64, kv ...interface{}) *Counter {
+	c := New(base)
+	for i := 0; i < len(kv); i += 2 {
+		c.Set(kv[i].(string), kv[i+1].(float64))
+	}
+
+	return c
+}
+
+func logDistribution(c *Counter) *Counter {
+	result := c.Copy()
+	result.Log()
+
+	return result
+}
+
+func TestInformation(t *testing.T) {
+	p := distribution(0.0, "a", 0.5, "b", 0.5)
+	q := distribution(0.0, "a", 0.25, "b", 0.25, "c", 0.5)
+	lp, lq := logDistribution(p), logDistribution(q)
+
+	tests := []struct {
+		name          string
+		value, expect float64
+	}{
+		{"H(p)", Entropy(p), math.Ln2},
+		{"H(q)", Entropy(q), 1.5 * math.Ln2},
+		{"H(p, q)", CrossEntropy(p, q), 2 * math.Ln2},
+		{"KL(p || q)", KL(p, q), math.Ln2},
+		{"JS(p, q)", JS(p, q), 0.5*math.Log(4.0/3.0) + 0.25*math.Log(2.0/3.0) + 0.25*math.Ln2},
+		{"log H(q)", LogEntropy(lq), 1.5 * math.Ln2},
+		{"log H(p, q)", LogCrossEntropy(lp, lq), 2 * math.Ln2},
+		{"log KL(p || q)", LogKL(lp, lq), math.Ln2},
+		{"log JS(p, q)", LogJS(lp, lq), JS(p, q)},
+	}
+
+	for _, test := range tests {
+		if math.Abs(test.value-test.expect) > 1e-12 {
+			t.Errorf("%s = %f, expected %f", test.name, test.value, test.expect)
+		}
+	}
+
+	if kl := KL(q, p); !math.IsInf(kl, 1) {
+		t.Errorf("KL(q || p) = %f, expected +Inf", kl)
+	}
+
+	if js := JS(p, distribution(0.0, "c", 1.0)); math.Abs(js-math.Ln2) > 1e-12 {
+		t.Errorf("JS of disjoint distributions = %f, expected ln 2", js)
+	}
+
+	// A smoothed q gives p's unseen keys a default
+	if kl := KL(p, distribution(0.25, "a", 0.5)); math.Abs(kl-0.5*math.Ln2) > 1e-12 {
+		t.Errorf("KL against a default = %f", kl)
+	}
+}
+
+func TestMutualInformation(t *testing.T) {
+	independent := NewConditional(0.0)
+	dependent := NewConditional(0.0)
+	for _, x := range []string{"a", "b"} {
+		for _, y := range []string{"c", "d"} {
+			independent.Set(x, y, 2.0)
+		}
+	}
+	dependent.Set("a", "c", 1.0)
+	dependent.Set("b", "d", 1.0)
+
+	if mi := MutualInformation(independent); math.Abs(mi) > 1e-12 {
+		t.Errorf("MI of independent variables = %f", mi)
+	}
+
+	if mi := MutualInformation(dependent); math.Abs(mi-math.Ln2) > 1e-12 {
+		t.Errorf("MI = %f, expected ln 2", mi)
+	}
+
+	logJoint := NewConditional(math.Inf(-1))
+	logJoint.Set("a", "c", math.Log(0.5))
+	logJoint.Set("b", "d", math.Log(0.5))
+	if mi := LogMutualInformation(logJoint); math.Abs(mi-math.Ln2) > 1e-12 {
+		t.Errorf("Log MI = %f, expected ln 2", mi)
+	}
+}
+
+func TestMutualInformationNoMass(t *testing.T) {
+	if mi := MutualInformation(NewConditional(0.0)); mi != 0 {
+		t.Errorf("MI of an empty joint = %f, expected 0", mi)
+	}
+
+	zero := NewConditional(0.0)
+	zero.Given("a")
+	if mi := MutualInformation(zero); mi != 0 {
+		t.Errorf("MI of a zero joint = %f, expected 0", mi)
+	}
+}
@@ -0,0 +1,240 @@
+package counter
+
+import "fmt"
+import "math"
+
+// A counter over arbitrary keys, e.g. ints or interned pointers to
+// (label, feature) pairs, so they needn't be formatted as strings and
+// parsed back. Keys are interface{} values, and must be usable as map
+// keys (numbers, strings, pointers, channels or interfaces holding
+// them). nil isn't a valid key. Counter is a Keyed with string keys.
+type Keyed struct {
+	values map[interface{}]float64
+	// default value for missing items
+	Base float64
+}
+
+func NewKeyed(base float64) *Keyed {
+	return &Keyed{make(map[interface{}]float64), base}
+}
+
+// Return a value for a key (falling back to the default)
+func (c *Keyed) Get(k interface{}) float64 {
+	if v, ok := c.values[k]; ok {
+		return v
+	}
+	return c.Base
+}
+
+// Set a value for a key
+func (c *Keyed) Set(k interface{}, v float64) {
+	if v == c.Base {
+		c.values[k] = 0, false
+		return
+	}
+
+	c.values[k] = v
+}
+
+// Increment a value
+func (c *Keyed) Incr(k interface{}) {
+	c.Set(k, c.Get(k)+1)
+}
+
+// Return a new counter with the same values as c
+func (c *Keyed) Copy() *Keyed {
+	result := NewKeyed(c.Base)
+
+	for k, v := range c.values {
+		result.values[k] = v
+	}
+
+	return result
+}
+
+// Return a list of keys for this counter
+func (c *Keyed) Keys() []interface{} {
+	result := make([]interface{}, 0, len(c.values))
+
+	for k, _ := range c.values {
+		result = append(result, k)
+	}
+
+	return result
+}
+
+func (c *Keyed) String() string {
+	s := "Keyed: {"
+
+	for k, v := range c.values {
+		s += fmt.Sprintf("%v: %f, ", k, v)
+	}
+
+	s += "}"
+
+	return s
+}
+
+// Apply an operation on two counters, updating the first
+func (a *Keyed) operate(b *Keyed, op func(a, b float64) float64) {
+	keys := a.Keys()
+	for k, _ := range b.values {
+		if _, ok := a.values[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	values := make([]float64, len(keys))
+	for i, k := range keys {
+		values[i] = op(a.Get(k), b.Get(k))
+	}
+
+	a.Base = op(a.Base, b.Base)
+	for i, k := range keys {
+		a.Set(k, values[i])
+	}
+}
+
+// Apply an operation on two counters, returning a new counter
+func operateKeyed(a, b *Keyed, op func(a, b float64) float64) *Keyed {
+	result := a.Copy()
+	result.operate(b, op)
+
+	return result
+}
+
+// Add a to b, returning a new counter
+func AddKeyed(a, b *Keyed) *Keyed {
+	return operateKeyed(a, b, func(a, b float64) float64 { return a + b })
+}
+
+// Subtract b from a, returning a new counter
+func SubtractKeyed(a, b *Keyed) *Keyed {
+	return operateKeyed(a, b, func(a, b float64) float64 { return a - b })
+}
+
+// Multiply a by b, returning a new counter
+func MultiplyKeyed(a, b *Keyed) *Keyed {
+	return operateKeyed(a, b, func(a, b float64) float64 { return a * b })
+}
+
+// Divide a by b, returning a new counter
+func DivideKeyed(a, b *Keyed) *Keyed {
+	return operateKeyed(a, b, func(a, b float64) float64 { return a / b })
+}
+
+// Add a to b in log space, returning a new counter
+func LogAddKeyed(a, b *Keyed) *Keyed {
+	return operateKeyed(a, b, logAdd)
+}
+
+// Add o to c
+func (c *Keyed) Add(o *Keyed) {
+	c.operate(o, func(a, b float64) float64 { return a + b })
+}
+
+// Subtract o from c
+func (c *Keyed) Subtract(o *Keyed) {
+	c.operate(o, func(a, b float64) float64 { return a - b })
+}
+
+// Multiply c by o
+func (c *Keyed) Multiply(o *Keyed) {
+	c.operate(o, func(a, b float64) float64 { return a * b })
+}
+
+// Divide c by o
+func (c *Keyed) Divide(o *Keyed) {
+	c.operate(o, func(a, b float64) float64 { return a / b })
+}
+
+// Add o to c in log space, i.e. ln(exp(c) + exp(o))
+func (c *Keyed) LogAdd(o *Keyed) {
+	c.operate(o, logAdd)
+}
+
+// Apply a function to every value in the counter, including the
+// default (for which the key is nil)
+func (c *Keyed) Apply(op func(k interface{}, a float64) float64) {
+	c.Base = op(nil, c.Base)
+
+	for k, v := range c.values {
+		c.Set(k, op(k, v))
+	}
+}
+
+// Log every value in the counter (including the default)
+func (c *Keyed) Log() {
+	c.Apply(func(k interface{}, f float64) float64 { return math.Log(f) })
+}
+
+// Exponentiate every value in the counter (including the default)
+func (c *Keyed) Exp() {
+	c.Apply(func(k interface{}, f float64) float64 { return math.Exp(f) })
+}
+
+// The sum over values (not including the default)
+func (c *Keyed) Sum() float64 {
+	sum := 0.0
+	for _, v := range c.values {
+		sum += v
+	}
+
+	return sum
+}
+
+// Normalize a counter s.t. the sum over values is now 1.0
+func (c *Keyed) Normalize() {
+	sum := c.Sum()
+	c.Apply(func(k interface{}, a float64) float64 { return a / sum })
+}
+
+// Normalize into a log-distribution
+func (c *Keyed) LogNormalize() {
+	logSum := math.Log(c.Sum())
+	c.Apply(func(k interface{}, a float64) float64 { return math.Log(a) - logSum })
+}
+
+// ln(sum(exp(v))) over the values in the counter (not including the
+// default), computed without overflow
+func (c *Keyed) LogSumExp() float64 {
+	max := math.Inf(-1)
+	for _, v := range c.values {
+		if v > max {
+			max = v
+		}
+	}
+
+	if math.IsInf(max, 0) {
+		return max
+	}
+
+	sum := 0.0
+	for _, v := range c.values {
+		sum += math.Exp(v - max)
+	}
+
+	return max + math.Log(sum)
+}
+
+// Normalize a log-distribution s.t. the sum over exp(values) is now
+// 1.0, without leaving log space
+func (c *Keyed) LogRenormalize() {
+	logSum := c.LogSumExp()
+	c.Apply(func(k interface{}, a float64) float64 { return a - logSum })
+}
+
+// The key with the largest value and its value, or nil and the default
+// for an empty counter
+func (c *Keyed) ArgMax() (interface{}, float64) {
+	var maxKey interface{} = nil
+	maxVal := c.Base
+
+	for k, v := range c.values {
+		if maxKey == nil || v > maxVal {
+			maxKey, maxVal = k, v
+		}
+	}
+
+	return maxKey, maxVal
+}
@@ -0,0 +1,93 @@
+package counter
+
+import "math"
+import "testing"
+
+type pair struct {
+	label, feature string
+}
+
+func TestKeyed(t *testing.T) {
+	// Interned pairs, so the pointers are the keys
+	a, b := &pair{"pos", "good"}, &pair{"neg", "good"}
+
+	c := NewKeyed(0.0)
+	c.Incr(a)
+	c.Incr(a)
+	c.Incr(b)
+	c.Set(3, 1.0)
+
+	if c.Get(a) != 2 || c.Get(&pair{"pos", "good"}) != 0 || c.Get(3) != 1 {
+		t.Errorf("Bad counts %s", c)
+	}
+
+	c.Normalize()
+	if c.Get(a) != 0.5 {
+		t.Errorf("Expected 0.5, got %f", c.Get(a))
+	}
+
+	o := NewKeyed(1.0)
+	o.Set(b, 3.0)
+	c.Add(o)
+	if c.Get(b) != 3.25 || c.Get(a) != 1.5 || c.Get("missing") != 1 {
+		t.Errorf("Bad sum %s", c)
+	}
+
+	c.Set(a, 1.0)
+	if len(c.Keys()) != 2 {
+		t.Errorf("Setting a key to the default should remove it, got %v", c.Keys())
+	}
+
+	d := NewKeyed(0.0)
+	d.Set(1, 1.0)
+	d.Set(2, 3.0)
+	d.LogNormalize()
+	if math.Abs(d.Get(2)-math.Log(0.75)) > 1e-12 || !math.IsInf(d.Get(3), -1) {
+		t.Errorf("Bad log distribution %s", d)
+	}
+}
+
+func TestKeyedArithmetic(t *testing.T) {
+	a, b := NewKeyed(0.0), NewKeyed(1.0)
+	a.Set(1, 2.0)
+	a.Set(2, 4.0)
+	b.Set(2, 2.0)
+
+	sum, diff := AddKeyed(a, b), SubtractKeyed(a, b)
+	if sum.Get(1) != 3 || sum.Get(2) != 6 || sum.Base != 1 || diff.Get(1) != 1 || diff.Get(2) != 2 {
+		t.Errorf("Bad sum %s or difference %s", sum, diff)
+	}
+
+	prod, quot := MultiplyKeyed(a, b), DivideKeyed(a, b)
+	if prod.Get(1) != 2 || prod.Get(2) != 8 || quot.Get(2) != 2 || quot.Base != 0 {
+		t.Errorf("Bad product %s or quotient %s", prod, quot)
+	}
+
+	if a.Get(1) != 2 || b.Get(1) != 1 {
+		t.Errorf("Package level operations changed their arguments")
+	}
+
+	if k, v := a.ArgMax(); k != 2 || v != 4 {
+		t.Errorf("ArgMax = %v, %f, expected 2, 4", k, v)
+	}
+
+	if k, v := NewKeyed(0.5).ArgMax(); k != nil || v != 0.5 {
+		t.Errorf("ArgMax of an empty counter = %v, %f", k, v)
+	}
+}
+
+func TestCounterWrapsKeyed(t *testing.T) {
+	c := New(0.0)
+	c.Set("a", 1.0)
+	c.Set("b", -2.0)
+	c.Set("b", 0.0)
+
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "a" || c.Keyed.Get("a") != 1 {
+		t.Errorf("Bad keys %v", keys)
+	}
+
+	c.Set("b", -3.0)
+	if k, v := c.ArgMax(); k != "a" || v != 1 {
+		t.Errorf("ArgMax = %s, %f, expected a, 1", k, v)
+	}
+}
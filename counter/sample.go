@@ -0,0 +1,176 @@
+package counter
+
+import "fmt"
+import "math"
+import "rand"
+import "sort"
+
+// Sampling from counters of (possibly unnormalized) non-negative
+// weights, or of log weights. Keys are visited in sorted order, so a
+// seeded source reproduces the same samples. The default value isn't
+// sampled.
+
+// Pick the key at which the running total of weights passes target
+func pick(keys []string, weights []float64, target float64) string {
+	for i, w := range weights {
+		target -= w
+		if target < 0 {
+			return keys[i]
+		}
+	}
+
+	// Rounding error - fall back to the last key with any weight
+	for i := len(keys) - 1; i >= 0; i-- {
+		if weights[i] > 0 {
+			return keys[i]
+		}
+	}
+
+	panic("can't sample from a counter with no weight")
+}
+
+// The keys of c in sorted order, along with their weights
+func (c *Counter) weights(transform func(float64) float64) ([]string, []float64, float64) {
+	keys := c.Keys()
+	sort.Strings(keys)
+
+	weights := make([]float64, len(keys))
+	total := 0.0
+	for i, k := range keys {
+		weights[i] = transform(c.Get(k))
+		total += weights[i]
+	}
+
+	if total <= 0 || math.IsNaN(total) {
+		panic("can't sample from a counter with no weight")
+	}
+
+	return keys, weights, total
+}
+
+// exp(v - max), for turning log weights into weights without overflow
+func (c *Counter) shiftedExp() func(float64) float64 {
+	max := math.Inf(-1)
+	for _, k := range c.Keys() {
+		if v := c.Get(k); v > max {
+			max = v
+		}
+	}
+
+	return func(v float64) float64 { return math.Exp(v - max) }
+}
+
+// Draw a key with probability proportional to its value
+func (c *Counter) Sample(r *rand.Rand) string {
+	keys, weights, total := c.weights(identity)
+
+	return pick(keys, weights, r.Float64()*total)
+}
+
+// Draw a key from a counter of log weights, e.g. after LogNormalize
+func (c *Counter) LogSample(r *rand.Rand) string {
+	keys, weights, total := c.weights(c.shiftedExp())
+
+	return pick(keys, weights, r.Float64()*total)
+}
+
+// A table for drawing many samples from a fixed distribution in
+// constant time each, using Walker's alias method (in Vose's variant)
+type Alias struct {
+	keys []string
+	// The chance of keeping the column's own key rather than its alias
+	prob  []float64
+	alias []int
+}
+
+// Build an alias table over keys with the given (non-negative,
+// possibly unnormalized) weights, one per key
+func NewAliasTable(keys []string, weights []float64) *Alias {
+	n := len(keys)
+	if len(weights) != n {
+		panic(fmt.Sprintf("alias table has %d keys but %d weights", n, len(weights)))
+	}
+
+	a := &Alias{keys: keys, prob: make([]float64, n), alias: make([]int, n)}
+
+	total := 0.0
+	for i, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			panic(fmt.Sprintf("alias table weight for %q is %f, not non-negative", keys[i], w))
+		}
+		total += w
+	}
+
+	if n == 0 || total <= 0 {
+		panic("can't sample from a counter with no weight")
+	}
+
+	scaled := make([]float64, n)
+	small, large := []int{}, []int{}
+	for i, w := range weights {
+		scaled[i] = w * float64(n) / total
+
+		if scaled[i] < 1.0 {
+			small = append(small, i)
+		} else {
+			large = append(large, i)
+		}
+	}
+
+	for len(small) > 0 && len(large) > 0 {
+		s, l := small[len(small)-1], large[len(large)-1]
+		small = small[:len(small)-1]
+
+		a.prob[s] = scaled[s]
+		a.alias[s] = l
+
+		// l gives the rest of s's column its mass
+		scaled[l] -= 1.0 - scaled[s]
+		if scaled[l] < 1.0 {
+			large = large[:len(large)-1]
+			small = append(small, l)
+		}
+	}
+
+	// Whatever's left fills its own column (up to rounding error)
+	for _, i := range append(small, large...) {
+		a.prob[i] = 1.0
+		a.alias[i] = i
+	}
+
+	return a
+}
+
+// Build an alias table for a counter of weights
+func NewAlias(c *Counter) *Alias {
+	keys, weights, _ := c.weights(identity)
+
+	return NewAliasTable(keys, weights)
+}
+
+// Build an alias table for a counter of log weights
+func NewLogAlias(c *Counter) *Alias {
+	keys, weights, _ := c.weights(c.shiftedExp())
+
+	return NewAliasTable(keys, weights)
+}
+
+// Draw a key
+func (a *Alias) Sample(r *rand.Rand) string {
+	i := r.Intn(len(a.keys))
+	if r.Float64() < a.prob[i] {
+		return a.keys[i]
+	}
+
+	return a.keys[a.alias[i]]
+}
+
+// Draw n keys
+func (a *Alias) Samples(r *rand.Rand, n int) []string {
+	result := make([]string, n)
+	for i := range result {
+		result[i] = a.Sample(r)
+	}
+
+	return result
+}
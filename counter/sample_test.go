@@ -0,0 +1,77 @@
+package counter
+
+import "math"
+import "rand"
+import "testing"
+
+// Check that n samples from draw follow the normalized distribution p
+func checkSamples(t *testing.T, name string, p *Counter, draw func() string) {
+	n := 20000
+	seen := New(0.0)
+	for i := 0; i < n; i++ {
+		seen.Incr(draw())
+	}
+
+	for _, k := range seen.Keys() {
+		if p.Get(k) == 0 {
+			t.Errorf("%s: sampled %s, which has no weight", name, k)
+		}
+	}
+
+	for _, k := range p.Keys() {
+		if f := seen.Get(k) / float64(n); math.Abs(f-p.Get(k)) > 0.02 {
+			t.Errorf("%s: sampled %s %.3f of the time, expected %.3f", name, k, f, p.Get(k))
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	weights := New(0.0)
+	weights.Set("a", 1.0)
+	weights.Set("b", 2.0)
+	weights.Set("c", 5.0)
+	weights.Set("d", 0.0)
+
+	p := weights.Copy()
+	p.Normalize()
+	logWeights := weights.Copy()
+	logWeights.Log()
+
+	r := rand.New(rand.NewSource(1))
+	alias, logAlias := NewAlias(weights), NewLogAlias(logWeights)
+
+	checkSamples(t, "Sample", p, func() string { return weights.Sample(r) })
+	checkSamples(t, "LogSample", p, func() string { return logWeights.LogSample(r) })
+	checkSamples(t, "Alias", p, func() string { return alias.Sample(r) })
+	checkSamples(t, "LogAlias", p, func() string { return logAlias.Sample(r) })
+
+	// Seeded sources are reproducible
+	a := alias.Samples(rand.New(rand.NewSource(7)), 10)
+	b := alias.Samples(rand.New(rand.NewSource(7)), 10)
+	for i := range a {
+		if a[i] != b[i] || weights.Sample(rand.New(rand.NewSource(int64(i)))) != weights.Sample(rand.New(rand.NewSource(int64(i)))) {
+			t.Fatalf("Samples with the same seed differ")
+		}
+	}
+}
+
+func TestAliasTableErrors(t *testing.T) {
+	bad := map[string][]float64{
+		"more weights than keys":  {1.0, 2.0, 3.0},
+		"fewer weights than keys": {1.0},
+		"a negative weight":       {1.0, -1.0},
+		"no weight":               {0.0, 0.0},
+	}
+
+	for name, weights := range bad {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for %s", name)
+				}
+			}()
+
+			NewAliasTable([]string{"a", "b"}, weights)
+		}()
+	}
+}
@@ -0,0 +1,113 @@
+package counter
+
+import "container/heap"
+import "sort"
+
+// Keys ordered by value, largest first, with ties broken by key
+type byValue struct {
+	keys   []string
+	values []float64
+}
+
+func (s *byValue) Len() int { return len(s.keys) }
+func (s *byValue) Less(i, j int) bool {
+	if s.values[i] == s.values[j] {
+		return s.keys[i] < s.keys[j]
+	}
+	return s.values[i] > s.values[j]
+}
+func (s *byValue) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+// A min-heap in byValue order - the root is the worst key, so that it's
+// the one dropped when the heap grows past k
+type worstFirst struct {
+	byValue
+}
+
+func (h *worstFirst) Less(i, j int) bool { return h.byValue.Less(j, i) }
+
+func (h *worstFirst) Push(x interface{}) {
+	h.keys = append(h.keys, x.(string))
+}
+
+func (h *worstFirst) Pop() interface{} {
+	n := len(h.keys) - 1
+	key := h.keys[n]
+	h.keys, h.values = h.keys[:n], h.values[:n]
+
+	return key
+}
+
+// Return the keys of this counter in sorted order
+func (c *Counter) SortedKeys() []string {
+	keys := c.Keys()
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Return the keys of this counter, largest value first (ties broken by
+// key)
+func (c *Counter) KeysByValue() []string {
+	s := &byValue{keys: c.Keys()}
+	s.values = make([]float64, len(s.keys))
+	for i, k := range s.keys {
+		s.values[i] = c.values[k]
+	}
+
+	sort.Sort(s)
+	return s.keys
+}
+
+// Return the (at most) n keys with the largest values, largest first
+// (ties broken by key)
+func (c *Counter) TopK(n int) []string {
+	if n <= 0 {
+		return []string{}
+	}
+
+	h := &worstFirst{}
+	for _, k := range c.Keys() {
+		h.values = append(h.values, c.values[k])
+		heap.Push(h, k)
+
+		if h.Len() > n {
+			heap.Pop(h)
+		}
+	}
+
+	result := make([]string, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(h).(string)
+	}
+
+	return result
+}
+
+// Remove every key with a value below threshold (so that it takes the
+// default value)
+func (c *Counter) Prune(threshold float64) {
+	for k, v := range c.values {
+		if v < threshold {
+			c.values[k] = 0, false
+		}
+	}
+}
+
+// Remove every key but the n with the largest values (ties broken by
+// key)
+func (c *Counter) KeepTop(n int) {
+	keep := make(map[string]bool)
+	for _, k := range c.TopK(n) {
+		keep[k] = true
+	}
+
+	for k, _ := range c.values {
+		if !keep[k.(string)] {
+			c.values[k] = 0, false
+		}
+	}
+}
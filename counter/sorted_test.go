@@ -0,0 +1,49 @@
+package counter
+
+import "strings"
+import "testing"
+
+func words(text string) *Counter {
+	c := New(0.0)
+	for _, w := range strings.Fields(text) {
+		c.Incr(w)
+	}
+
+	return c
+}
+
+func TestSorted(t *testing.T) {
+	c := words("b a c a b a d e e")
+
+	if got := strings.Join(c.SortedKeys(), " "); got != "a b c d e" {
+		t.Errorf("SortedKeys = %s", got)
+	}
+
+	if got := strings.Join(c.KeysByValue(), " "); got != "a b e c d" {
+		t.Errorf("KeysByValue = %s", got)
+	}
+
+	for n, expect := range []string{"", "a", "a b", "a b e", "a b e c", "a b e c d", "a b e c d"} {
+		if got := strings.Join(c.TopK(n), " "); got != expect {
+			t.Errorf("TopK(%d) = %s, expected %s", n, got, expect)
+		}
+	}
+
+	if s := c.String(); s != "Counter: {'a': 3.000000, 'b': 2.000000, 'c': 1.000000, 'd': 1.000000, 'e': 2.000000, }" {
+		t.Errorf("String() = %s", s)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	c := words("b a c a b a d e e")
+	c.Prune(2.0)
+	if got := strings.Join(c.SortedKeys(), " "); got != "a b e" || c.Get("c") != 0 {
+		t.Errorf("Prune(2) left %s", c)
+	}
+
+	c = words("b a c a b a d e e")
+	c.KeepTop(2)
+	if got := strings.Join(c.SortedKeys(), " "); got != "a b" {
+		t.Errorf("KeepTop(2) left %s", c)
+	}
+}
@@ -17,15 +17,18 @@ type Datum struct {
 	class    string
 	features []string
 
-	featureCounts *frozencounter.Counter
+	featureCounts *frozencounter.Sparse
 }
 
 func (d Datum) String() string {
 	return fmt.Sprintf("%s: %s", d.class, d.features)
 }
 
-func tally(data []Datum) (counts *frozencounter.CounterVector, features *frozencounter.KeySet, labels []string) {
+// Count features per label. If ks is nil, a keyset is built from every
+// feature seen in data.
+func tally(data []Datum, ks *frozencounter.KeySet) (counts *frozencounter.CounterVector, features *frozencounter.KeySet, labels []string) {
 	rawCounts := map[string]*counter.Counter{}
+	all := counter.New(0.0)
 
 	datumCounts := []*counter.Counter{}
 	for _, datum := range data {
@@ -37,18 +40,27 @@ func tally(data []Datum) (counts *frozencounter.CounterVector, features *frozenc
 		for _, f := range datum.features {
 			rawCounts[datum.class].Incr(f)
 			c.Incr(f)
+			all.Incr(f)
 		}
 
 		datumCounts = append(datumCounts, c)
 	}
 
-	for idx, c := range frozencounter.FreezeMany(datumCounts) {
-		data[idx].featureCounts = c
+	features = ks
+	if features == nil {
+		features = frozencounter.NewKeySet(all.Keys(), 0.0)
 	}
 
-	counts = frozencounter.NewCounterVector(frozencounter.FreezeMap(rawCounts))
+	// Each datum only has a few of the features, so store them sparsely
+	for idx, c := range datumCounts {
+		data[idx].featureCounts = frozencounter.FreezeSparse(c, features)
+	}
+
+	counts, err := frozencounter.NewCounterVector(frozencounter.FreezeMapWithKeySet(rawCounts, features))
+	if err != nil {
+		panic(err)
+	}
 
-	features = data[0].featureCounts.Keys
 	for label, _ := range counts.Extract() {
 		labels = append(labels, label)
 	}
@@ -65,15 +77,12 @@ type maxentWeights struct {
 }
 
 // Calculate the label distribution of features given weights, storing the result in out
-func (w *maxentWeights) labelDistribution(counts *frozencounter.Counter, weights *frozencounter.CounterVector) *frozencounter.Counter {
-	out := counter.New(0.0)
-
-	for label, featureWeights := range weights.Extract() {
-		out.Set(label, featureWeights.DotProduct(counts))
-	}
+func (w *maxentWeights) labelDistribution(counts *frozencounter.Sparse, weights *frozencounter.CounterVector) *frozencounter.Counter {
+	out := weights.MultiplySparse(counts)
 
-	out.LogNormalize()
-	return frozencounter.Freeze(out)
+	// The scores are unnormalized log probabilities
+	out.LogRenormalize()
+	return out
 }
 
 // given distribution for each datum, what's the expected count
@@ -81,9 +90,7 @@ func (w *maxentWeights) expectedCounts(labelDistribution []*frozencounter.Counte
 	expectedCounts = w.counts.Clone()
 
 	for idx, datum := range w.data {
-		for _, label := range w.labels {
-			expectedCounts.Get(label).AddScaled(labelDistribution[idx].Get(label), datum.featureCounts)
-		}
+		expectedCounts.AddOuterSparse(1.0, labelDistribution[idx], datum.featureCounts)
 	}
 
 	return
@@ -169,8 +176,19 @@ func (w *maxentWeights) Value(Weights minimizer.Vector) (value float64) {
 }
 
 func Train(data []Datum, l *log.Logger) *MaxEnt {
+	return train(data, nil, l)
+}
+
+// Train using features hashed into 2^bits buckets rather than a
+// keyset of every feature in data. Panics unless 1 <= bits <=
+// frozencounter.MaxHashBits.
+func TrainHashed(data []Datum, bits uint, l *log.Logger) *MaxEnt {
+	return train(data, frozencounter.NewHashedKeySet(bits, true), l)
+}
+
+func train(data []Datum, ks *frozencounter.KeySet, l *log.Logger) *MaxEnt {
 	l.Println("Building features")
-	counts, features, labels := tally(data)
+	counts, features, labels := tally(data, ks)
 
 	weightFn := &maxentWeights{sigma: 0.01, data: data, counts: counts, features: features, labels: labels, l: l}
 
@@ -181,11 +199,12 @@ func Train(data []Datum, l *log.Logger) *MaxEnt {
 }
 
 func (me *MaxEnt) Classify(features []string) (label string, score float64) {
-	counts := frozencounter.New(me.scorer.features)
+	c := counter.New(0.0)
 	for _, feature := range features {
-		counts.Incr(feature)
+		c.Incr(feature)
 	}
 
+	counts := frozencounter.FreezeSparse(c, me.scorer.features)
 	logProbs := me.scorer.labelDistribution(counts, me.Weights)
 
 	label, score = logProbs.ArgMax()
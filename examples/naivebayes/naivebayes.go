@@ -15,28 +15,19 @@ type Datum struct {
 
 func Train(data []Datum) *NaiveBayes {
 	class := counter.New(0.0)
-	features := make(map[string]*counter.Counter)
+	features := counter.NewConditional(0.0)
 
 	for _, datum := range data {
 		class.Incr(datum.class)
 		for _, f := range datum.features {
-			dist, ok := features[f]
-
-			if !ok {
-				dist = counter.New(0.0)
-				features[f] = dist
-			}
-
-			dist.Incr(datum.class)
+			features.Incr(f, datum.class)
 		}
 	}
 
 	class.LogNormalize()
-	for _, dist := range features {
-		dist.LogNormalize()
-	}
+	features.LogNormalize()
 
-	frozenFeatures := frozencounter.FreezeMap(features)
+	frozenFeatures := frozencounter.FreezeMap(features.Counters())
 
 	var keyset *frozencounter.KeySet
 	for _, dist := range frozenFeatures {
@@ -55,8 +46,8 @@ func (nb *NaiveBayes) Classify(features []string) (string, float64) {
 		score.Add(nb.FeatureLogDistributions[f])
 	}
 
+	score.LogRenormalize()
 	score.Exp()
-	score.Normalize()
 
 	c, probability := score.ArgMax()
 	return c, probability
@@ -0,0 +1,124 @@
+package features
+
+import "bufio"
+import "exp/norm"
+import "io"
+import "os"
+import "strings"
+import "unicode"
+
+// Placeholders substituted for numbers and URLs
+const (
+	NumberToken = "<num>"
+	URLToken    = "<url>"
+)
+
+// Unicode normalization to the canonical (NFC) and compatibility (NFKC)
+// composed forms. NFKC also folds ligatures, full width forms etc.
+var NFC Stage = MapText(func(s string) string { return norm.NFC.String(s) })
+var NFKC Stage = MapText(func(s string) string { return norm.NFKC.String(s) })
+
+// Case folding
+var Lower Stage = MapText(strings.ToLower)
+
+func stripAccents(s string) string {
+	s = strings.Map(func(r int) int {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(s))
+
+	return norm.NFC.String(s)
+}
+
+// Accent stripping, by decomposing each token and dropping its
+// combining marks (café -> cafe)
+var StripAccents Stage = MapText(stripAccents)
+
+func isNumber(s string) bool {
+	if len(s) > 1 && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+
+	return len(s) > 0 && matchNumber(s, 0) == len(s)
+}
+
+// Replace tokens that are numbers (as recognised by Treebank) with
+// NumberToken
+var ReplaceNumbers Stage = MapText(func(s string) string {
+	if isNumber(s) {
+		return NumberToken
+	}
+	return s
+})
+
+// Replace tokens that are URLs with URLToken
+var ReplaceURLs Stage = MapText(func(s string) string {
+	if len(s) > 0 && matchURL(s, 0) == len(s) {
+		return URLToken
+	}
+	return s
+})
+
+// A common list of English stopwords
+var EnglishStopwords = []string{
+	"a", "about", "above", "after", "again", "against", "all", "am", "an",
+	"and", "any", "are", "as", "at", "be", "because", "been", "before",
+	"being", "below", "between", "both", "but", "by", "can", "could", "did",
+	"do", "does", "doing", "down", "during", "each", "few", "for", "from",
+	"further", "had", "has", "have", "having", "he", "her", "here", "hers",
+	"herself", "him", "himself", "his", "how", "i", "if", "in", "into", "is",
+	"it", "its", "itself", "just", "me", "more", "most", "my", "myself", "no",
+	"nor", "not", "now", "of", "off", "on", "once", "only", "or", "other",
+	"our", "ours", "ourselves", "out", "over", "own", "same", "she", "should",
+	"so", "some", "such", "than", "that", "the", "their", "theirs", "them",
+	"themselves", "then", "there", "these", "they", "this", "those",
+	"through", "to", "too", "under", "until", "up", "very", "was", "we",
+	"were", "what", "when", "where", "which", "while", "who", "whom", "why",
+	"will", "with", "would", "you", "your", "yours", "yourself", "yourselves",
+}
+
+// Read a stopword list with one word per line. Blank lines and lines
+// starting with # are ignored.
+func LoadStopwords(r io.Reader) ([]string, os.Error) {
+	result := []string{}
+	in := bufio.NewReader(r)
+
+	for {
+		line, err := in.ReadString('\n')
+		if err != nil && err != os.EOF {
+			return nil, err
+		}
+
+		if word := strings.TrimSpace(line); word != "" && word[0] != '#' {
+			result = append(result, word)
+		}
+
+		if err == os.EOF {
+			return result, nil
+		}
+	}
+
+	panic("unreachable")
+}
+
+// A stage removing tokens that are (ignoring case) in words
+func Stopwords(words []string) Stage {
+	stop := make(map[string]bool, len(words))
+	for _, w := range words {
+		stop[strings.ToLower(w)] = true
+	}
+
+	return func(tokens []Token) []Token {
+		result := make([]Token, 0, len(tokens))
+
+		for _, t := range tokens {
+			if !stop[strings.ToLower(t.Text)] {
+				result = append(result, t)
+			}
+		}
+
+		return result
+	}
+}
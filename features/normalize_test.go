@@ -0,0 +1,76 @@
+package features
+
+import "bytes"
+import "strings"
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		stage    Stage
+		in, want string
+	}{
+		{NFC, "café", "café"},
+		{NFKC, "ﬁne", "fine"},
+		{Lower, "The", "the"},
+		{StripAccents, "naïve", "naive"},
+		{StripAccents, "café", "cafe"},
+		{ReplaceNumbers, "3.14", NumberToken},
+		{ReplaceNumbers, "-1,000", NumberToken},
+		{ReplaceNumbers, "3rd", "3rd"},
+		{ReplaceURLs, "http://example.com/a?b=c", URLToken},
+		{ReplaceURLs, "example", "example"},
+	}
+
+	for _, test := range tests {
+		if got := test.stage([]Token{Token{test.in, 0, len(test.in)}})[0].Text; got != test.want {
+			t.Errorf("%q -> %q, expected %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestStopwords(t *testing.T) {
+	words, err := LoadStopwords(strings.NewReader("# a comment\nfoo\n\n  bar\nbaz"))
+	if err != nil || strings.Join(words, " ") != "foo bar baz" {
+		t.Fatalf("LoadStopwords = %v, %v", words, err)
+	}
+
+	tokens := Stopwords(words)(Whitespace("Foo x bar y"))
+	if got := strings.Join(Strings(tokens), " "); got != "x y" {
+		t.Errorf("Got %q, expected \"x y\"", got)
+	}
+
+	if tokens[1].Start != 10 {
+		t.Errorf("Stopword removal should keep offsets")
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p := NewPipeline("treebank").Add("nfkc").Add("lower").Add("numbers").Add("urls").Add("stopwords").Add("porter")
+
+	buf := &bytes.Buffer{}
+	if err := p.Save(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadPipeline(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "The 3 cats were running to http://example.com"
+	want := "<num> cat run <url>"
+	for _, pipeline := range []*Pipeline{p, loaded} {
+		tokens := Strings(pipeline.MustCompile()(text))
+		if got := strings.Join(tokens, " "); got != want {
+			t.Errorf("Got %q, expected %q", got, want)
+		}
+	}
+
+	if _, err := NewPipeline("treebank").Add("unknown").Compile(); err == nil {
+		t.Errorf("Expected an error for an unknown stage")
+	}
+
+	if _, err := NewPipeline("treebank").Add("lower", "x").Compile(); err == nil {
+		t.Errorf("Expected an error for unexpected arguments")
+	}
+}
@@ -0,0 +1,124 @@
+package features
+
+import "fmt"
+import "io"
+import "json"
+import "os"
+
+// A serializable description of a preprocessing pipeline - a tokenizer
+// followed by a list of stages, all referred to by name. Saving the
+// pipeline used for training and loading it when serving ensures both
+// apply identical preprocessing.
+type Pipeline struct {
+	Tokenizer string
+	Stages    []StageSpec
+}
+
+type StageSpec struct {
+	Name string
+	// Stage specific arguments, e.g. the words of a stopword list
+	Args []string
+}
+
+var tokenizers = map[string]Tokenizer{
+	"whitespace": Whitespace,
+	"treebank":   Treebank,
+}
+
+func fixed(s Stage) func([]string) (Stage, os.Error) {
+	return func(args []string) (Stage, os.Error) {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("unexpected arguments %q", args)
+		}
+		return s, nil
+	}
+}
+
+var stageBuilders = map[string]func(args []string) (Stage, os.Error){
+	"nfc":           fixed(NFC),
+	"nfkc":          fixed(NFKC),
+	"lower":         fixed(Lower),
+	"strip-accents": fixed(StripAccents),
+	"numbers":       fixed(ReplaceNumbers),
+	"urls":          fixed(ReplaceURLs),
+	"porter":        fixed(Stem(Porter)),
+	"snowball":      fixed(Stem(Snowball)),
+	// The built-in English list unless given words
+	"stopwords": func(args []string) (Stage, os.Error) {
+		if len(args) == 0 {
+			return Stopwords(EnglishStopwords), nil
+		}
+		return Stopwords(args), nil
+	},
+}
+
+// Make a tokenizer available to pipelines under name
+func RegisterTokenizer(name string, t Tokenizer) {
+	tokenizers[name] = t
+}
+
+// Make a stage available to pipelines under name; build constructs the
+// stage from its arguments
+func RegisterStage(name string, build func(args []string) (Stage, os.Error)) {
+	stageBuilders[name] = build
+}
+
+func NewPipeline(tokenizer string) *Pipeline {
+	return &Pipeline{Tokenizer: tokenizer}
+}
+
+// Append a stage to the pipeline, returning the pipeline
+func (p *Pipeline) Add(stage string, args ...string) *Pipeline {
+	p.Stages = append(p.Stages, StageSpec{Name: stage, Args: args})
+	return p
+}
+
+// Build the tokenizer the pipeline describes
+func (p *Pipeline) Compile() (Tokenizer, os.Error) {
+	t, ok := tokenizers[p.Tokenizer]
+	if !ok {
+		return nil, fmt.Errorf("pipeline: unknown tokenizer %q", p.Tokenizer)
+	}
+
+	stages := make([]Stage, 0, len(p.Stages))
+	for _, spec := range p.Stages {
+		build, ok := stageBuilders[spec.Name]
+		if !ok {
+			return nil, fmt.Errorf("pipeline: unknown stage %q", spec.Name)
+		}
+
+		stage, err := build(spec.Args)
+		if err != nil {
+			return nil, fmt.Errorf("pipeline: stage %q: %s", spec.Name, err)
+		}
+
+		stages = append(stages, stage)
+	}
+
+	return Chain(t, stages...), nil
+}
+
+// Compile, panicking on error
+func (p *Pipeline) MustCompile() Tokenizer {
+	t, err := p.Compile()
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Write the pipeline to w as JSON
+func (p *Pipeline) Save(w io.Writer) os.Error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// Read a pipeline written by Save
+func LoadPipeline(r io.Reader) (*Pipeline, os.Error) {
+	p := &Pipeline{}
+	if err := json.NewDecoder(r).Decode(p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -0,0 +1,256 @@
+package features
+
+import "strings"
+
+// The Porter stemmer, following Martin Porter's reference C
+// implementation (including its departures from the 1980 paper: bli ->
+// ble rather than abli -> able, and the extra logi -> log rule).
+// Words are expected to be lower case.
+
+type porter struct {
+	b []byte
+	// b[:k+1] is the current word
+	k int
+	// the end of the stem when a suffix has been matched by ends
+	j int
+}
+
+func (z *porter) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !z.cons(i-1)
+	}
+
+	return true
+}
+
+// The number of vowel-consonant sequences in b[:j+1]
+func (z *porter) m() int {
+	n, i := 0, 0
+
+	for ; i <= z.j && z.cons(i); i++ {
+	}
+
+	for i <= z.j {
+		for ; i <= z.j && !z.cons(i); i++ {
+		}
+		if i > z.j {
+			break
+		}
+
+		n++
+		for ; i <= z.j && z.cons(i); i++ {
+		}
+	}
+
+	return n
+}
+
+func (z *porter) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (z *porter) doubleC(j int) bool {
+	return j >= 1 && z.b[j] == z.b[j-1] && z.cons(j)
+}
+
+// consonant-vowel-consonant ending at i, where the last consonant
+// isn't w, x or y
+func (z *porter) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+
+	c := z.b[i]
+	return c != 'w' && c != 'x' && c != 'y'
+}
+
+func (z *porter) ends(s string) bool {
+	if len(s) > z.k+1 || string(z.b[z.k+1-len(s):z.k+1]) != s {
+		return false
+	}
+
+	z.j = z.k - len(s)
+	return true
+}
+
+func (z *porter) setTo(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = z.j + len(s)
+}
+
+func (z *porter) r(s string) {
+	if z.m() > 0 {
+		z.setTo(s)
+	}
+}
+
+// Plurals and -ed or -ing
+func (z *porter) step1ab() {
+	if z.b[z.k] == 's' {
+		if z.ends("sses") {
+			z.k -= 2
+		} else if z.ends("ies") {
+			z.setTo("i")
+		} else if z.b[z.k-1] != 's' {
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+	} else if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+
+		switch {
+		case z.ends("at"):
+			z.setTo("ate")
+		case z.ends("bl"):
+			z.setTo("ble")
+		case z.ends("iz"):
+			z.setTo("ize")
+		case z.doubleC(z.k):
+			if c := z.b[z.k]; c != 'l' && c != 's' && c != 'z' {
+				z.k--
+			}
+		case z.m() == 1 && z.cvc(z.k):
+			z.setTo("e")
+		}
+	}
+}
+
+// Terminal y to i when there's another vowel in the stem
+func (z *porter) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// Try each suffix -> replacement pair in turn, stopping at the first
+// suffix that matches (whether or not it's replaced)
+func (z *porter) replace(rules []string) {
+	for i := 0; i < len(rules); i += 2 {
+		if z.ends(rules[i]) {
+			z.r(rules[i+1])
+			return
+		}
+	}
+}
+
+var porterStep2 = map[byte][]string{
+	'a': {"ational", "ate", "tional", "tion"},
+	'c': {"enci", "ence", "anci", "ance"},
+	'e': {"izer", "ize"},
+	'l': {"bli", "ble", "alli", "al", "entli", "ent", "eli", "e", "ousli", "ous"},
+	'o': {"ization", "ize", "ation", "ate", "ator", "ate"},
+	's': {"alism", "al", "iveness", "ive", "fulness", "ful", "ousness", "ous"},
+	't': {"aliti", "al", "iviti", "ive", "biliti", "ble"},
+	'g': {"logi", "log"},
+}
+
+// Double suffixes to single ones
+func (z *porter) step2() {
+	if z.k > 0 {
+		z.replace(porterStep2[z.b[z.k-1]])
+	}
+}
+
+var porterStep3 = map[byte][]string{
+	'e': {"icate", "ic", "ative", "", "alize", "al"},
+	'i': {"iciti", "ic"},
+	'l': {"ical", "ic", "ful", ""},
+	's': {"ness", ""},
+}
+
+// -ic-, -full, -ness etc.
+func (z *porter) step3() {
+	z.replace(porterStep3[z.b[z.k]])
+}
+
+var porterStep4 = map[byte][]string{
+	'a': {"al"},
+	'c': {"ance", "ence"},
+	'e': {"er"},
+	'i': {"ic"},
+	'l': {"able", "ible"},
+	'n': {"ant", "ement", "ment", "ent"},
+	'o': {"ion", "ou"},
+	's': {"ism"},
+	't': {"ate", "iti"},
+	'u': {"ous"},
+	'v': {"ive"},
+	'z': {"ize"},
+}
+
+// Remove -ant, -ence etc. when m > 1
+func (z *porter) step4() {
+	if z.k < 1 {
+		return
+	}
+
+	for _, suffix := range porterStep4[z.b[z.k-1]] {
+		if !z.ends(suffix) {
+			continue
+		}
+
+		if suffix == "ion" && (z.j < 0 || (z.b[z.j] != 's' && z.b[z.j] != 't')) {
+			continue
+		}
+
+		if z.m() > 1 {
+			z.k = z.j
+		}
+		return
+	}
+}
+
+// Remove a final -e and change -ll to -l when m > 1
+func (z *porter) step5() {
+	z.j = z.k
+
+	if z.b[z.k] == 'e' {
+		if a := z.m(); a > 1 || a == 1 && !z.cvc(z.k-1) {
+			z.k--
+		}
+	}
+
+	if z.b[z.k] == 'l' && z.doubleC(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
+
+// Stem a (lower case) word with the Porter stemmer
+func Porter(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	z := &porter{b: []byte(word), k: len(word) - 1}
+
+	z.step1ab()
+	if z.k > 0 {
+		z.step1c()
+		z.step2()
+		z.step3()
+		z.step4()
+		z.step5()
+	}
+
+	return string(z.b[:z.k+1])
+}
+
+// A stage stemming every token with stem (e.g. Porter or Snowball),
+// lower casing them first
+func Stem(stem func(string) string) Stage {
+	return MapText(func(w string) string { return stem(strings.ToLower(w)) })
+}
@@ -0,0 +1,374 @@
+package features
+
+import counter "gnlp/counter"
+import "math"
+import "strings"
+import "unicode"
+
+// Sentence boundary detection. Sentences are returned as tokens
+// spanning the source text, so sentence splitters are Tokenizers.
+// Boundaries are only placed between whitespace separated words, after
+// a word ending in sentence final punctuation (optionally followed by
+// closing quotes or brackets).
+
+const (
+	openingPunctuation = "\"'([{“‘«"
+	closingPunctuation = "\"')]}”’»"
+)
+
+// The kind of sentence final punctuation a word ends with
+const (
+	noEnd = iota
+	periodEnd
+	ellipsisEnd
+	// ! or ?
+	exclamationEnd
+)
+
+// Strip opening and closing punctuation from a word
+func core(word string) string {
+	return strings.TrimRight(strings.TrimLeft(word, openingPunctuation), closingPunctuation)
+}
+
+func ending(word string) int {
+	switch {
+	case strings.HasSuffix(word, "...") || strings.HasSuffix(word, "…"):
+		return ellipsisEnd
+	case strings.HasSuffix(word, "."):
+		return periodEnd
+	case strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?"):
+		return exclamationEnd
+	}
+
+	return noEnd
+}
+
+// The lower case type of a word, without its final period
+func wordType(word string) string {
+	return strings.ToLower(strings.TrimRight(core(word), "."))
+}
+
+// Initials, e.g. the J. in J. Smith
+func isInitial(word string) bool {
+	w := []int(strings.TrimRight(core(word), "."))
+	return len(w) == 1 && unicode.IsLetter(w[0])
+}
+
+// -1 if word starts with a lower case letter, 1 if it starts with an
+// upper case one, and 0 otherwise
+func firstCase(word string) int {
+	for _, r := range core(word) {
+		switch {
+		case unicode.IsLower(r):
+			return -1
+		case unicode.IsUpper(r):
+			return 1
+		}
+		break
+	}
+
+	return 0
+}
+
+func hasLetter(word string) bool {
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Split text into sentences, where boundary decides whether a word
+// ending in sentence final punctuation ends a sentence given the next
+// word
+func splitSentences(text string, boundary func(word, next string) bool) []Token {
+	result := []Token{}
+	words := Whitespace(text)
+	start := -1
+
+	for i, w := range words {
+		if start < 0 {
+			start = w.Start
+		}
+
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1].Text
+		}
+
+		if next == "" || (ending(core(w.Text)) != noEnd && boundary(core(w.Text), next)) {
+			result = append(result, token(text, start, w.End))
+			start = -1
+		}
+	}
+
+	return result
+}
+
+// The rule based decision: a sentence ends unless the next word starts
+// in lower case, or the period belongs to a known abbreviation or an
+// initial
+func ruleBoundary(word, next string) bool {
+	if firstCase(next) < 0 {
+		return false
+	}
+
+	switch ending(word) {
+	case ellipsisEnd:
+		return firstCase(next) > 0
+	case periodEnd:
+		return !isAbbreviation(wordType(word), core(next)) && !isInitial(word)
+	}
+
+	return true
+}
+
+// Split text into sentences with simple rules
+func Sentences(text string) []Token {
+	return splitSentences(text, ruleBoundary)
+}
+
+var _ Tokenizer = Sentences
+
+// Orthographic contexts a word type has been seen in
+const (
+	upperStart = 1 << iota
+	lowerStart
+	upperInternal
+	lowerInternal
+)
+
+// An unsupervised sentence splitter, after Kiss & Strunk (2006),
+// "Unsupervised Multilingual Sentence Boundary Detection"
+type Punkt struct {
+	// Word types (lower case, without a final period) that are
+	// abbreviations
+	Abbreviations map[string]bool
+	// Word types that frequently start sentences
+	SentenceStarters map[string]bool
+	// Word type -> the orthographic contexts it has been seen in
+	Orthography map[string]int
+}
+
+// Collects the statistics needed to learn a Punkt model from raw text
+type PunktTrainer struct {
+	// Word type -> count with and without a final period
+	periods, noPeriods *counter.Counter
+	words              []string
+}
+
+func NewPunktTrainer() *PunktTrainer {
+	return &PunktTrainer{periods: counter.New(0.0), noPeriods: counter.New(0.0)}
+}
+
+// Add a document of raw text to the training data
+func (t *PunktTrainer) Observe(text string) {
+	for _, w := range Whitespace(text) {
+		word := core(w.Text)
+		t.words = append(t.words, word)
+
+		if !hasLetter(word) || ending(word) == ellipsisEnd {
+			continue
+		}
+
+		if ending(word) == periodEnd {
+			t.periods.Incr(wordType(word))
+		} else {
+			t.noPeriods.Incr(wordType(word))
+		}
+	}
+}
+
+// x * ln(y), where 0 * ln(0) = 0
+func xlogy(x, y float64) float64 {
+	if x == 0 {
+		return 0.0
+	}
+
+	return x * math.Log(y)
+}
+
+// Dunning's log likelihood ratio for a word occurring a times, b of
+// them with a period, against periods occurring with probability p
+func abbreviationLikelihood(a, ab, p float64) float64 {
+	null := xlogy(ab, p) + xlogy(a-ab, 1.0-p)
+	alt := xlogy(ab, 0.99) + xlogy(a-ab, 0.01)
+
+	return -2.0 * (null - alt)
+}
+
+// Dunning's log likelihood ratio for the collocation of a (occurring a
+// times) and b (occurring b times), occurring together ab times out of
+// n
+func collocationLikelihood(a, b, ab, n float64) float64 {
+	p := b / n
+	p1 := ab / a
+	p2 := (b - ab) / (n - a)
+
+	null := xlogy(ab, p) + xlogy(a-ab, 1.0-p) + xlogy(b-ab, p) + xlogy(n-a-b+ab, 1.0-p)
+	alt := xlogy(ab, p1) + xlogy(a-ab, 1.0-p1) + xlogy(b-ab, p2) + xlogy(n-a-b+ab, 1.0-p2)
+
+	return -2.0 * (null - alt)
+}
+
+// Learn a model from the observed text
+func (t *PunktTrainer) Estimate() *Punkt {
+	p := &Punkt{
+		Abbreviations:    make(map[string]bool),
+		SentenceStarters: make(map[string]bool),
+		Orthography:      make(map[string]int),
+	}
+
+	// Abbreviations: types that strongly collocate with a final period,
+	// penalised for their length and for occurrences without one
+	n := t.periods.Sum() + t.noPeriods.Sum()
+	periodRate := t.periods.Sum() / n
+
+	for _, typ := range t.periods.Keys() {
+		withPeriod, without := t.periods.Get(typ), t.noPeriods.Get(typ)
+		length := float64(len([]int(strings.Replace(typ, ".", "", -1))))
+
+		score := abbreviationLikelihood(withPeriod+without, withPeriod, periodRate)
+		score *= math.Exp(-length)
+		score *= float64(strings.Count(typ, ".") + 1)
+		score *= math.Pow(length, -without)
+
+		if score >= 0.3 {
+			p.Abbreviations[typ] = true
+		}
+	}
+
+	// Orthographic contexts and sentence starters, taking a period after
+	// anything but an abbreviation or initial as a sentence boundary
+	starters, types := counter.New(0.0), counter.New(0.0)
+	breaks := 0.0
+	context := 0 // 1 at the start of a sentence, -1 internal and 0 unknown
+
+	for i, word := range t.words {
+		if i == 0 {
+			context = 1
+		}
+
+		typ := wordType(word)
+		if hasLetter(typ) {
+			types.Incr(typ)
+
+			switch c := firstCase(word); {
+			case c > 0 && context > 0:
+				p.Orthography[typ] |= upperStart
+			case c < 0 && context > 0:
+				p.Orthography[typ] |= lowerStart
+			case c > 0 && context < 0:
+				p.Orthography[typ] |= upperInternal
+			case c < 0 && context < 0:
+				p.Orthography[typ] |= lowerInternal
+			}
+
+			if context > 0 && i > 0 {
+				starters.Incr(typ)
+			}
+		}
+
+		switch ending(word) {
+		case noEnd:
+			context = -1
+		case periodEnd:
+			if p.Abbreviations[typ] || isInitial(word) {
+				context = 0
+			} else {
+				context = 1
+				breaks++
+			}
+		default:
+			context = 0
+		}
+	}
+
+	total := types.Sum()
+	for _, typ := range starters.Keys() {
+		together, count := starters.Get(typ), types.Get(typ)
+
+		if total/breaks > count/together && collocationLikelihood(breaks, count, together, total) >= 30.0 {
+			p.SentenceStarters[typ] = true
+		}
+	}
+
+	return p
+}
+
+// Learn a model from raw text
+func TrainPunkt(texts <-chan string) *Punkt {
+	t := NewPunktTrainer()
+	for text := range texts {
+		t.Observe(text)
+	}
+
+	return t.Estimate()
+}
+
+// Does the orthography of next suggest a sentence boundary before it?
+// 1 if so, -1 if it suggests there isn't one and 0 if unsure.
+func (p *Punkt) orthographic(next string) int {
+	o := p.Orthography[wordType(next)]
+
+	switch firstCase(next) {
+	case 1:
+		// Capitalised, but only ever seen in lower case within sentences
+		if o&(lowerStart|lowerInternal) != 0 && o&upperInternal == 0 {
+			return 1
+		}
+	case -1:
+		if o&(upperStart|upperInternal) != 0 || o&lowerStart == 0 {
+			return -1
+		}
+	}
+
+	return 0
+}
+
+func (p *Punkt) boundary(word, next string) bool {
+	typ := wordType(word)
+
+	switch ending(word) {
+	case exclamationEnd:
+		return firstCase(next) >= 0
+	case ellipsisEnd:
+		return p.orthographic(next) > 0 || (firstCase(next) > 0 && p.SentenceStarters[wordType(next)])
+	}
+
+	if p.Abbreviations[typ] || isInitial(word) {
+		return firstCase(next) > 0 && (p.orthographic(next) > 0 || p.SentenceStarters[wordType(next)])
+	}
+
+	return firstCase(next) >= 0 || p.orthographic(next) >= 0
+}
+
+// Split text into sentences
+func (p *Punkt) Sentences(text string) []Token {
+	return splitSentences(text, func(word, next string) bool { return p.boundary(word, next) })
+}
+
+// The model as a sentence splitter
+func (p *Punkt) Tokenizer() Tokenizer {
+	return func(text string) []Token { return p.Sentences(text) }
+}
+
+// Split text into sentences and then the sentences into words, with
+// word offsets relative to the whole text
+func Segment(text string, sentences, words Tokenizer) [][]Token {
+	result := [][]Token{}
+
+	for _, s := range sentences(text) {
+		tokens := words(s.Text)
+		for i := range tokens {
+			tokens[i].Start += s.Start
+			tokens[i].End += s.Start
+		}
+
+		result = append(result, tokens)
+	}
+
+	return result
+}
@@ -0,0 +1,70 @@
+package features
+
+import "strings"
+import "testing"
+
+func sentenceTexts(sentences []Token) string {
+	return strings.Join(Strings(sentences), " | ")
+}
+
+func TestSentences(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"It costs 3.50 dollars. Mr. Smith paid.", "It costs 3.50 dollars. | Mr. Smith paid."},
+		{"He said \"Stop!\" Then he left.", "He said \"Stop!\" | Then he left."},
+		{"Wait... what? No... Really.", "Wait... what? | No... | Really."},
+		{"J. R. R. Tolkien wrote it (in 1937.) Really?", "J. R. R. Tolkien wrote it (in 1937.) | Really?"},
+		{"The answer is no. We left.", "The answer is no. | We left."},
+		{"It opens on Dec. 5 at noon. Come early.", "It opens on Dec. 5 at noon. | Come early."},
+		{"We met in Dec. It snowed.", "We met in Dec. | It snowed."},
+		{"See No. 5 for details. Thanks.", "See No. 5 for details. | Thanks."},
+		{"no final punctuation", "no final punctuation"},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := sentenceTexts(Sentences(test.in)); got != test.want {
+			t.Errorf("Sentences(%q) = %q, expected %q", test.in, got, test.want)
+		}
+	}
+
+	text := "One two.  Three four."
+	if s := Sentences(text); len(s) != 2 || text[s[1].Start:s[1].End] != "Three four." {
+		t.Errorf("Bad sentence offsets %v", s)
+	}
+}
+
+var punktCorpus = `The meeting lasted ca. three hours. The committee met again on Monday.
+It was ca. nine o'clock when they finished. Nobody was happy. They
+voted on the budget of ca. two million. Then the chair closed the
+meeting. The minutes were sent to ca. forty members. Many of them
+replied. The vote passed with ca. sixty percent. Everyone went home.
+The report cost ca. ten thousand. It was approved. The next meeting is
+in ca. two weeks. The chair will attend. The budget grew by ca.
+five percent. It is large.`
+
+func TestPunkt(t *testing.T) {
+	texts := make(chan string, 1)
+	texts <- punktCorpus
+	close(texts)
+
+	p := TrainPunkt(texts)
+
+	if !p.Abbreviations["ca"] {
+		t.Fatalf("Expected ca to be learnt as an abbreviation, got %v", p.Abbreviations)
+	}
+
+	if p.Abbreviations["meeting"] || p.Abbreviations["percent"] {
+		t.Errorf("Ordinary words learnt as abbreviations: %v", p.Abbreviations)
+	}
+
+	got := sentenceTexts(p.Sentences("The trip took ca. five hours. The chair went home. It was late."))
+	want := "The trip took ca. five hours. | The chair went home. | It was late."
+	if got != want {
+		t.Errorf("Got %q, expected %q", got, want)
+	}
+
+	words := Segment("Hi there. Bye now.", p.Tokenizer(), Treebank)
+	if len(words) != 2 || len(words[1]) != 3 || words[1][0].Start != 10 {
+		t.Errorf("Bad segmentation %v", words)
+	}
+}
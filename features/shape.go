@@ -0,0 +1,142 @@
+package features
+
+import "unicode"
+
+// Markers added around words before taking character n-grams, so that
+// n-grams at the edges of a word are distinguished from those inside it
+const (
+	WordStart = "<"
+	WordEnd   = ">"
+)
+
+// The character n-grams (for n from min to max) of a word, with
+// boundary markers, as features, e.g. CharNGrams("cat", 2, 2) =
+// "char=<c", "char=ca", "char=at", "char=t>"
+func CharNGrams(word string, min, max int) []string {
+	chars := []int(WordStart + word + WordEnd)
+	result := []string{}
+
+	for n := min; n <= max; n++ {
+		for start := 0; start+n <= len(chars); start++ {
+			result = append(result, "char="+string(chars[start:start+n]))
+		}
+	}
+
+	return result
+}
+
+// The prefixes of a word up to max characters long
+func Prefixes(word string, max int) []string {
+	chars := []int(word)
+	result := []string{}
+
+	for n := 1; n <= max && n <= len(chars); n++ {
+		result = append(result, "prefix="+string(chars[:n]))
+	}
+
+	return result
+}
+
+// The suffixes of a word up to max characters long
+func Suffixes(word string, max int) []string {
+	chars := []int(word)
+	result := []string{}
+
+	for n := 1; n <= max && n <= len(chars); n++ {
+		result = append(result, "suffix="+string(chars[len(chars)-n:]))
+	}
+
+	return result
+}
+
+// The shape of a word: upper case letters become X, other letters x
+// and digits d, while everything else is kept (Smith -> Xxxxx, 12-34 ->
+// dd-dd)
+func Shape(word string) string {
+	shape := []int{}
+
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			r = 'X'
+		case unicode.IsLetter(r):
+			r = 'x'
+		case unicode.IsDigit(r):
+			r = 'd'
+		}
+
+		shape = append(shape, r)
+	}
+
+	return string(shape)
+}
+
+// Shape, with runs of the same character collapsed (Smith -> Xx,
+// 1999-2000 -> d-d)
+func ShortShape(word string) string {
+	shape := []int{}
+
+	for _, r := range []int(Shape(word)) {
+		if len(shape) == 0 || shape[len(shape)-1] != r {
+			shape = append(shape, r)
+		}
+	}
+
+	return string(shape)
+}
+
+// Capitalization, digit and punctuation flags for a word
+func Flags(word string) []string {
+	upper, lower, digits, punct, letters, chars := 0, 0, 0, 0, 0, 0
+	first := true
+	initCap := false
+
+	for _, r := range word {
+		chars++
+
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		case unicode.IsDigit(r):
+			digits++
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			punct++
+		}
+
+		if unicode.IsLetter(r) {
+			letters++
+		}
+
+		if first {
+			initCap = unicode.IsUpper(r)
+			first = false
+		}
+	}
+
+	result := []string{}
+	add := func(flag string, set bool) {
+		if set {
+			result = append(result, flag)
+		}
+	}
+
+	add("initcap", initCap && lower > 0)
+	add("allcaps", upper > 0 && upper == letters)
+	add("mixedcaps", !initCap && upper > 0 && lower > 0)
+	add("hasdigit", digits > 0)
+	add("alldigits", digits > 0 && digits == chars)
+	add("haspunct", punct > 0)
+	add("allpunct", punct > 0 && punct == chars)
+	add("alphanumeric", letters > 0 && digits > 0 && letters+digits == chars)
+
+	return result
+}
+
+// The orthographic features of a word: its shape, short shape and flags
+func Orthographic(word string) []string {
+	result := []string{"shape=" + Shape(word), "shortshape=" + ShortShape(word)}
+
+	return append(result, Flags(word)...)
+}
@@ -0,0 +1,45 @@
+package features
+
+import "strings"
+import "testing"
+
+func TestShape(t *testing.T) {
+	cases := map[string]string{"Smith": "Xxxxx", "12-34": "dd-dd", "McDonald's": "XxXxxxxx'x", "é1": "xd"}
+
+	for word, shape := range cases {
+		if s := Shape(word); s != shape {
+			t.Errorf("Shape(%q) = %q, expected %q", word, s, shape)
+		}
+	}
+
+	if s := ShortShape("1999-2000"); s != "d-d" {
+		t.Errorf("ShortShape(1999-2000) = %q", s)
+	}
+}
+
+func TestCharNGrams(t *testing.T) {
+	if grams := strings.Join(CharNGrams("cat", 2, 3), " "); grams != "char=<c char=ca char=at char=t> char=<ca char=cat char=at>" {
+		t.Errorf("CharNGrams(cat) = %s", grams)
+	}
+
+	if s := strings.Join(Suffixes("naïve", 2), " "); s != "suffix=e suffix=ve" {
+		t.Errorf("Suffixes(naïve) = %s", s)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	cases := map[string]string{
+		"Smith": "initcap",
+		"IBM":   "allcaps",
+		"iPod":  "mixedcaps",
+		"1999":  "hasdigit alldigits",
+		"A4":    "allcaps hasdigit alphanumeric",
+		"--":    "haspunct allpunct",
+	}
+
+	for word, flags := range cases {
+		if f := strings.Join(Flags(word), " "); f != flags {
+			t.Errorf("Flags(%q) = %q, expected %q", word, f, flags)
+		}
+	}
+}
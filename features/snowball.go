@@ -0,0 +1,316 @@
+package features
+
+import "strings"
+
+// The Snowball English (Porter2) stemmer, as described at
+// http://snowball.tartarus.org/algorithms/english/stemmer.html. Words
+// are expected to be lower case.
+
+// Words with irregular stems
+var snowballExceptions = map[string]string{
+	"skis": "ski", "skies": "sky", "dying": "die", "lying": "lie", "tying": "tie",
+	"idly": "idl", "gently": "gentl", "ugly": "ugli", "early": "earli", "only": "onli",
+	"singly": "singl", "sky": "sky", "news": "news", "howe": "howe", "atlas": "atlas",
+	"cosmos": "cosmos", "bias": "bias", "andes": "andes",
+}
+
+// Words left alone once step 1a is done
+var snowballInvariants = map[string]bool{
+	"inning": true, "outing": true, "canning": true, "herring": true,
+	"earring": true, "proceed": true, "exceed": true, "succeed": true,
+}
+
+type snowball struct {
+	w      []byte
+	r1, r2 int
+}
+
+func isVowel(c byte) bool {
+	switch c {
+	case 'a', 'e', 'i', 'o', 'u', 'y':
+		return true
+	}
+
+	return false
+}
+
+// The start of the region after the first non-vowel following a vowel
+// in w[start:]
+func (z *snowball) region(start int) int {
+	for i := start + 1; i < len(z.w); i++ {
+		if !isVowel(z.w[i]) && isVowel(z.w[i-1]) {
+			return i + 1
+		}
+	}
+
+	return len(z.w)
+}
+
+func (z *snowball) hasSuffix(s string) bool {
+	return strings.HasSuffix(string(z.w), s)
+}
+
+// The longest of suffixes that w ends with, or ""
+func (z *snowball) longest(suffixes []string) string {
+	best := ""
+
+	for _, s := range suffixes {
+		if len(s) > len(best) && z.hasSuffix(s) {
+			best = s
+		}
+	}
+
+	return best
+}
+
+func (z *snowball) replace(suffix, replacement string) {
+	z.w = append(z.w[:len(z.w)-len(suffix)], replacement...)
+}
+
+// Is the stem before suffix in R1 (or R2)?
+func (z *snowball) inR1(suffix string) bool {
+	return len(z.w)-len(suffix) >= z.r1
+}
+
+func (z *snowball) inR2(suffix string) bool {
+	return len(z.w)-len(suffix) >= z.r2
+}
+
+// Does the stem w[:end] end in a short syllable?
+func (z *snowball) shortSyllable(end int) bool {
+	w := z.w[:end]
+
+	switch {
+	case len(w) == 2:
+		return isVowel(w[0]) && !isVowel(w[1])
+	case len(w) > 2:
+		c := w[len(w)-1]
+		return !isVowel(c) && c != 'w' && c != 'x' && c != 'Y' && isVowel(w[len(w)-2]) && !isVowel(w[len(w)-3])
+	}
+
+	return false
+}
+
+func (z *snowball) isShort() bool {
+	return z.r1 >= len(z.w) && z.shortSyllable(len(z.w))
+}
+
+// Does the word before suffix contain a vowel?
+func (z *snowball) vowelBefore(suffix string) bool {
+	for _, c := range z.w[:len(z.w)-len(suffix)] {
+		if isVowel(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (z *snowball) prelude() {
+	if len(z.w) > 0 && z.w[0] == '\'' {
+		z.w = z.w[1:]
+	}
+
+	// Consonant y's
+	for i, c := range z.w {
+		if c == 'y' && (i == 0 || isVowel(z.w[i-1])) {
+			z.w[i] = 'Y'
+		}
+	}
+
+	z.r1 = z.region(0)
+	for _, prefix := range []string{"gener", "commun", "arsen"} {
+		if strings.HasPrefix(string(z.w), prefix) {
+			z.r1 = len(prefix)
+		}
+	}
+
+	z.r2 = z.region(z.r1)
+}
+
+// Possessives
+func (z *snowball) step0() {
+	if s := z.longest([]string{"'s'", "'s", "'"}); s != "" {
+		z.replace(s, "")
+	}
+}
+
+// Plurals
+func (z *snowball) step1a() {
+	switch s := z.longest([]string{"sses", "ied", "ies", "us", "ss", "s"}); s {
+	case "sses":
+		z.replace(s, "ss")
+	case "ied", "ies":
+		if len(z.w) > 4 {
+			z.replace(s, "i")
+		} else {
+			z.replace(s, "ie")
+		}
+	case "s":
+		for i := 0; i < len(z.w)-2; i++ {
+			if isVowel(z.w[i]) {
+				z.replace(s, "")
+				break
+			}
+		}
+	}
+}
+
+// -ed, -ing and -ly
+func (z *snowball) step1b() {
+	switch s := z.longest([]string{"eed", "eedly", "ed", "edly", "ing", "ingly"}); s {
+	case "":
+		return
+	case "eed", "eedly":
+		if z.inR1(s) {
+			z.replace(s, "ee")
+		}
+	default:
+		if !z.vowelBefore(s) {
+			return
+		}
+		z.replace(s, "")
+
+		switch {
+		case z.hasSuffix("at"), z.hasSuffix("bl"), z.hasSuffix("iz"):
+			z.w = append(z.w, 'e')
+		case z.longest([]string{"bb", "dd", "ff", "gg", "mm", "nn", "pp", "rr", "tt"}) != "":
+			z.w = z.w[:len(z.w)-1]
+		case z.isShort():
+			z.w = append(z.w, 'e')
+		}
+	}
+}
+
+// Final y after a consonant
+func (z *snowball) step1c() {
+	n := len(z.w)
+	if n > 2 && (z.w[n-1] == 'y' || z.w[n-1] == 'Y') && !isVowel(z.w[n-2]) {
+		z.w[n-1] = 'i'
+	}
+}
+
+var snowballStep2 = map[string]string{
+	"tional": "tion", "enci": "ence", "anci": "ance", "abli": "able", "entli": "ent",
+	"izer": "ize", "ization": "ize", "ational": "ate", "ation": "ate", "ator": "ate",
+	"alism": "al", "aliti": "al", "alli": "al", "fulness": "ful", "ousli": "ous",
+	"ousness": "ous", "iveness": "ive", "iviti": "ive", "biliti": "ble", "bli": "ble",
+	"ogi": "og", "fulli": "ful", "lessli": "less", "li": "",
+}
+
+var snowballStep3 = map[string]string{
+	"tional": "tion", "ational": "ate", "alize": "al", "icate": "ic", "iciti": "ic",
+	"ical": "ic", "ful": "", "ness": "", "ative": "",
+}
+
+var snowballStep4 = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment",
+	"ent", "ism", "ate", "iti", "ous", "ive", "ize", "ion",
+}
+
+func suffixes(m map[string]string) []string {
+	result := make([]string, 0, len(m))
+	for k, _ := range m {
+		result = append(result, k)
+	}
+
+	return result
+}
+
+var snowballStep2Suffixes = suffixes(snowballStep2)
+var snowballStep3Suffixes = suffixes(snowballStep3)
+
+// The character before suffix
+func (z *snowball) before(suffix string) byte {
+	if i := len(z.w) - len(suffix) - 1; i >= 0 {
+		return z.w[i]
+	}
+
+	return 0
+}
+
+func (z *snowball) step2() {
+	s := z.longest(snowballStep2Suffixes)
+	if s == "" || !z.inR1(s) {
+		return
+	}
+
+	switch s {
+	case "ogi":
+		if z.before(s) != 'l' {
+			return
+		}
+	case "li":
+		if strings.Index("cdeghkmnrt", string(z.before(s))) < 0 {
+			return
+		}
+	}
+
+	z.replace(s, snowballStep2[s])
+}
+
+func (z *snowball) step3() {
+	s := z.longest(snowballStep3Suffixes)
+	if s == "" || !z.inR1(s) || (s == "ative" && !z.inR2(s)) {
+		return
+	}
+
+	z.replace(s, snowballStep3[s])
+}
+
+func (z *snowball) step4() {
+	s := z.longest(snowballStep4)
+	if s == "" || !z.inR2(s) {
+		return
+	}
+
+	if c := z.before(s); s == "ion" && c != 's' && c != 't' {
+		return
+	}
+
+	z.replace(s, "")
+}
+
+func (z *snowball) step5() {
+	n := len(z.w)
+
+	switch {
+	case n > 0 && z.w[n-1] == 'e':
+		if z.inR2("e") || (z.inR1("e") && !z.shortSyllable(n-1)) {
+			z.w = z.w[:n-1]
+		}
+	case n > 1 && z.w[n-1] == 'l' && z.w[n-2] == 'l':
+		if z.inR2("l") {
+			z.w = z.w[:n-1]
+		}
+	}
+}
+
+// Stem a (lower case) word with the Snowball English (Porter2) stemmer
+func Snowball(word string) string {
+	if stem, ok := snowballExceptions[word]; ok {
+		return stem
+	}
+
+	if len(word) <= 2 {
+		return word
+	}
+
+	z := &snowball{w: []byte(word)}
+	z.prelude()
+	z.step0()
+	z.step1a()
+
+	if snowballInvariants[string(z.w)] {
+		return string(z.w)
+	}
+
+	z.step1b()
+	z.step1c()
+	z.step2()
+	z.step3()
+	z.step4()
+	z.step5()
+
+	return strings.Replace(string(z.w), "Y", "y", -1)
+}
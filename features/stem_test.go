@@ -0,0 +1,66 @@
+package features
+
+import "strings"
+import "testing"
+
+// Pairs from Porter's paper and the reference vocabulary / output
+// files published with the stemmers
+var porterPairs = `caresses caress ponies poni ties ti caress caress cats cat feed feed
+agreed agre plastered plaster bled bled motoring motor sing sing conflated conflat
+troubled troubl sized size hopping hop tanned tan falling fall hissing hiss fizzed fizz
+failing fail filing file happy happi sky sky relational relat conditional condit
+rational ration valenci valenc hesitanci hesit digitizer digit conformabli conform
+radicalli radic differentli differ vileli vile analogousli analog vietnamization vietnam
+predication predic operator oper feudalism feudal decisiveness decis hopefulness hope
+callousness callous formaliti formal sensitiviti sensit sensibiliti sensibl triplicate triplic
+formative form formalize formal electriciti electr electrical electr hopeful hope
+goodness good revival reviv allowance allow inference infer airliner airlin
+gyroscopic gyroscop adjustable adjust defensible defens irritant irrit replacement replac
+adjustment adjust dependent depend adoption adopt homologou homolog communism commun
+activate activ angulariti angular homologous homolog effective effect bowdlerize bowdler
+probate probat rate rate cease ceas controll control roll roll generalizations gener
+oscillators oscil`
+
+var snowballPairs = `consign consign consigned consign consigning consign consignment consign
+consist consist consisted consist consistency consist consistent consist consistently consist
+consisting consist consists consist consolation consol consolations consol
+consolatory consolatori console consol consoled consol consoles consol consolidate consolid
+consolidated consolid consolidating consolid consoling consol consolingly consol consols consol
+consonant conson consort consort consorted consort consorting consort conspicuous conspicu
+conspicuously conspicu conspiracy conspiraci conspirator conspir conspirators conspir
+conspire conspir conspired conspir conspiring conspir constable constabl constables constabl
+constance constanc constancy constanc constant constant knack knack knackeries knackeri
+knacks knack knag knag knave knave knaves knave knavish knavish kneaded knead kneading knead
+knee knee kneel kneel kneeled kneel kneeling kneel kneels kneel knees knee knell knell
+knelt knelt knew knew knick knick knif knif knife knife knight knight knightly knight
+knights knight knit knit knits knit knitted knit knitting knit knives knive knob knob
+knobs knob knock knock knocked knock knocker knocker knockers knocker knocking knock
+knocks knock knopp knopp knot knot knots knot skies sky dying die cries cri ties tie
+generously generous communication communic gas gas gaps gap kiwis kiwi proceeding proceed
+succeeded succeed herring herring`
+
+func checkStems(t *testing.T, name string, stem func(string) string, pairs string) {
+	words := strings.Fields(pairs)
+
+	for i := 0; i+1 < len(words); i += 2 {
+		if s := stem(words[i]); s != words[i+1] {
+			t.Errorf("%s(%q) = %q, expected %q", name, words[i], s, words[i+1])
+		}
+	}
+}
+
+func TestPorter(t *testing.T) {
+	checkStems(t, "Porter", Porter, porterPairs)
+}
+
+func TestSnowball(t *testing.T) {
+	checkStems(t, "Snowball", Snowball, snowballPairs)
+}
+
+func TestStemStage(t *testing.T) {
+	tokens := Chain(Whitespace, Stem(Porter))("Ponies RELATIONAL")
+
+	if s := strings.Join(Strings(tokens), " "); s != "poni relat" || tokens[1].Start != 7 {
+		t.Errorf("Stemmed tokens: %v", tokens)
+	}
+}
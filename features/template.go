@@ -0,0 +1,256 @@
+package features
+
+import "fmt"
+import "os"
+import "strconv"
+import "strings"
+
+// Words used for positions before the start or after the end of a
+// sequence
+const (
+	PadStart = "<s>"
+	PadEnd   = "</s>"
+)
+
+// A compiled feature template, e.g. "w[-1]|w[0]" or "suffix3(w[0])".
+// Templates are made up of one or more parts separated by |, each part
+// being a word at an offset from the current position (w[-1], w[0],
+// w[+2]) optionally wrapped in functions (lower, upper, shape,
+// shortshape, prefixN, suffixN).
+type Template struct {
+	Name  string
+	parts []extractor
+}
+
+type extractor func(tokens []string, pos int) string
+
+var templateFunctions = map[string]func(n int) func(string) string{
+	"lower":      func(n int) func(string) string { return strings.ToLower },
+	"upper":      func(n int) func(string) string { return strings.ToUpper },
+	"shape":      func(n int) func(string) string { return Shape },
+	"shortshape": func(n int) func(string) string { return ShortShape },
+	"prefix": func(n int) func(string) string {
+		return func(w string) string {
+			if chars := []int(w); len(chars) > n {
+				return string(chars[:n])
+			}
+			return w
+		}
+	},
+	"suffix": func(n int) func(string) string {
+		return func(w string) string {
+			if chars := []int(w); len(chars) > n {
+				return string(chars[len(chars)-n:])
+			}
+			return w
+		}
+	},
+}
+
+type templateParser struct {
+	spec string
+	pos  int
+}
+
+func (p *templateParser) errorf(format string, args ...interface{}) os.Error {
+	return fmt.Errorf("template %q, position %d: %s", p.spec, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *templateParser) skipSpace() {
+	for p.pos < len(p.spec) && p.spec[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// Consume a run of characters matching valid
+func (p *templateParser) consume(valid func(c byte) bool) string {
+	start := p.pos
+	for p.pos < len(p.spec) && valid(p.spec[p.pos]) {
+		p.pos++
+	}
+
+	return p.spec[start:p.pos]
+}
+
+func (p *templateParser) expect(c byte) os.Error {
+	p.skipSpace()
+	if p.pos >= len(p.spec) || p.spec[p.pos] != c {
+		return p.errorf("expected '%c'", c)
+	}
+
+	p.pos++
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// part := name '(' part ')' | 'w' '[' offset ']'
+func (p *templateParser) part() (extractor, os.Error) {
+	p.skipSpace()
+	name := p.consume(isLetter)
+	digits := p.consume(isDigit)
+	p.skipSpace()
+
+	if p.pos < len(p.spec) && p.spec[p.pos] == '[' {
+		if name != "w" || digits != "" {
+			return nil, p.errorf("unknown attribute %q", name+digits)
+		}
+		p.pos++
+		p.skipSpace()
+
+		sign := p.consume(func(c byte) bool { return c == '+' || c == '-' })
+		offset, err := strconv.Atoi(p.consume(isDigit))
+		if err != nil || len(sign) > 1 {
+			return nil, p.errorf("bad offset")
+		}
+		if sign == "-" {
+			offset = -offset
+		}
+
+		if err := p.expect(']'); err != nil {
+			return nil, err
+		}
+
+		return func(tokens []string, pos int) string {
+			switch i := pos + offset; {
+			case i < 0:
+				return PadStart
+			case i >= len(tokens):
+				return PadEnd
+			default:
+				return tokens[i]
+			}
+			panic("unreachable")
+		}, nil
+	}
+
+	fn, ok := templateFunctions[name]
+	if !ok {
+		return nil, p.errorf("unknown function %q", name)
+	}
+
+	n := 0
+	if name != "prefix" && name != "suffix" {
+		if digits != "" {
+			return nil, p.errorf("%s takes no length", name)
+		}
+	} else if digits == "" {
+		return nil, p.errorf("%s needs a length, e.g. %s3", name, name)
+	} else if n, _ = strconv.Atoi(digits); n < 1 {
+		return nil, p.errorf("%s needs a length of at least 1", name)
+	}
+
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+
+	inner, err := p.part()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+
+	f := fn(n)
+	return func(tokens []string, pos int) string {
+		// Padding passes through functions untouched
+		w := inner(tokens, pos)
+		if w == PadStart || w == PadEnd {
+			return w
+		}
+
+		return f(w)
+	}, nil
+}
+
+// Compile a feature template
+func CompileTemplate(spec string) (*Template, os.Error) {
+	p := &templateParser{spec: spec}
+	t := &Template{Name: strings.Replace(spec, " ", "", -1)}
+
+	for {
+		part, err := p.part()
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, part)
+
+		p.skipSpace()
+		if p.pos == len(p.spec) {
+			return t, nil
+		}
+
+		if err := p.expect('|'); err != nil {
+			return nil, err
+		}
+	}
+
+	panic("unreachable")
+}
+
+// Compile a feature template, panicking if it's invalid
+func MustCompileTemplate(spec string) *Template {
+	t, err := CompileTemplate(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// The feature this template produces at position pos of tokens, e.g.
+// "w[-1]|w[0]=the|cat"
+func (t *Template) Apply(tokens []string, pos int) string {
+	values := make([]string, 0, len(t.parts))
+
+	for _, part := range t.parts {
+		values = append(values, part(tokens, pos))
+	}
+
+	return t.Name + "=" + strings.Join(values, "|")
+}
+
+// A set of templates applied together
+type Templates []*Template
+
+// Compile a list of feature templates
+func CompileTemplates(specs []string) (Templates, os.Error) {
+	result := make(Templates, 0, len(specs))
+
+	for _, spec := range specs {
+		t, err := CompileTemplate(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// The features produced by every template at position pos of tokens
+func (ts Templates) Apply(tokens []string, pos int) []string {
+	result := make([]string, 0, len(ts))
+
+	for _, t := range ts {
+		result = append(result, t.Apply(tokens, pos))
+	}
+
+	return result
+}
+
+// The features produced by every template at every position of tokens
+func (ts Templates) ApplyAll(tokens []string) [][]string {
+	result := make([][]string, 0, len(tokens))
+
+	for pos, _ := range tokens {
+		result = append(result, ts.Apply(tokens, pos))
+	}
+
+	return result
+}
@@ -0,0 +1,37 @@
+package features
+
+import "strings"
+import "testing"
+
+func TestTemplates(t *testing.T) {
+	templates, err := CompileTemplates([]string{"w[-1]|w[0]", "suffix3(w[0])", "shape(w[+1])", "lower(prefix2(w[-2]))"})
+	if err != nil {
+		t.Fatalf("Failed to compile templates: %v", err)
+	}
+
+	tokens := strings.Fields("The Cat sat")
+	expected := []string{
+		"w[-1]|w[0]=<s>|The suffix3(w[0])=The shape(w[+1])=Xxx lower(prefix2(w[-2]))=<s>",
+		"w[-1]|w[0]=The|Cat suffix3(w[0])=Cat shape(w[+1])=xxx lower(prefix2(w[-2]))=<s>",
+		"w[-1]|w[0]=Cat|sat suffix3(w[0])=sat shape(w[+1])=</s> lower(prefix2(w[-2]))=th",
+	}
+
+	for pos, features := range templates.ApplyAll(tokens) {
+		if s := strings.Join(features, " "); s != expected[pos] {
+			t.Errorf("Features at %d = %s, expected %s", pos, s, expected[pos])
+		}
+	}
+}
+
+func TestTemplateErrors(t *testing.T) {
+	bad := []string{
+		"w[x]", "x[0]", "suffix(w[0])", "shape(w[0]", "w[0]|", "bogus(w[0])",
+		"lower3(w[0])", "shape2(w[+1])", "prefix0(w[0])", "suffix0(w[0])",
+	}
+
+	for _, spec := range bad {
+		if _, err := CompileTemplate(spec); err == nil {
+			t.Errorf("Template %q should have been rejected", spec)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package features
+
+import "regexp"
+import "strings"
+import "unicode"
+import "utf8"
+
+// A token along with its byte offsets into the source text. Tokenizers
+// produce tokens s.t. source[Start:End] == Text; later stages (e.g.
+// stemming) may rewrite Text but keep the offsets.
+type Token struct {
+	Text       string
+	Start, End int
+}
+
+// Tokenizers split raw text into tokens
+type Tokenizer func(text string) []Token
+
+// Stages transform a list of tokens, e.g. by stemming them
+type Stage func(tokens []Token) []Token
+
+// A stage rewriting the text of every token with fn
+func MapText(fn func(string) string) Stage {
+	return func(tokens []Token) []Token {
+		result := make([]Token, 0, len(tokens))
+
+		for _, t := range tokens {
+			t.Text = fn(t.Text)
+			result = append(result, t)
+		}
+
+		return result
+	}
+}
+
+// A tokenizer running t and then each of stages in turn
+func Chain(t Tokenizer, stages ...Stage) Tokenizer {
+	return func(text string) []Token {
+		tokens := t(text)
+		for _, stage := range stages {
+			tokens = stage(tokens)
+		}
+
+		return tokens
+	}
+}
+
+func token(text string, start, end int) Token {
+	return Token{Text: text[start:end], Start: start, End: end}
+}
+
+// Strip the offsets from a list of tokens
+func Strings(tokens []Token) []string {
+	result := make([]string, 0, len(tokens))
+
+	for _, t := range tokens {
+		result = append(result, t.Text)
+	}
+
+	return result
+}
+
+// Split text on runs of whitespace
+func Whitespace(text string) []Token {
+	tokens := []Token{}
+	start := -1
+
+	for pos, r := range text {
+		if unicode.IsSpace(r) {
+			if start >= 0 {
+				tokens = append(tokens, token(text, start, pos))
+				start = -1
+			}
+		} else if start < 0 {
+			start = pos
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token(text, start, len(text)))
+	}
+
+	return tokens
+}
+
+// A tokenizer producing every (non-overlapping) match of re
+func Regexp(re *regexp.Regexp) Tokenizer {
+	return func(text string) []Token {
+		tokens := []Token{}
+
+		for _, match := range re.FindAllStringIndex(text, -1) {
+			if match[1] > match[0] {
+				tokens = append(tokens, token(text, match[0], match[1]))
+			}
+		}
+
+		return tokens
+	}
+}
+
+// Abbreviations that keep their trailing period
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true,
+	"jr": true, "sr": true, "vs": true, "etc": true, "inc": true, "ltd": true,
+	"co": true, "corp": true, "jan": true, "feb": true, "apr": true, "jun": true,
+	"jul": true, "aug": true, "sept": true, "oct": true, "nov": true, "mt": true,
+	"ft": true,
+}
+
+// Words that often end sentences, so are only abbreviations when
+// followed by a number, e.g. No. 5 or Dec. 25
+var numberAbbreviations = map[string]bool{"no": true, "mar": true, "sep": true, "dec": true}
+
+// Whether word (lower case, without its period) is an abbreviation
+// when followed by next
+func isAbbreviation(word, next string) bool {
+	if abbreviations[word] {
+		return true
+	}
+
+	return numberAbbreviations[word] && next != "" && next[0] >= '0' && next[0] <= '9'
+}
+
+var emoticons = []string{
+	":-)", ":)", ":-(", ":(", ";-)", ";)", ":-D", ":D", ":-P", ":P", ":-p", ":p",
+	":'(", ":-/", ":/", ":-|", ":|", ":-O", ":O", ":-o", ":o", "=)", "=(", "<3",
+	"^_^", "^^", "XD", "xD",
+}
+
+var urlPrefixes = []string{"http://", "https://", "ftp://", "www."}
+
+// Words that are split even though they contain no apostrophe
+var fusedWords = map[string]int{"cannot": 3, "gonna": 3, "gotta": 3, "wanna": 3}
+
+var contractionSuffixes = []string{"'s", "'m", "'d", "'re", "'ve", "'ll", "’s", "’m", "’d", "’re", "’ve", "’ll"}
+
+func isWordRune(r int) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isApostrophe(r int) bool {
+	return r == '\'' || r == '’'
+}
+
+// The rune at text[pos:] (or utf8.RuneError at the end of the text)
+func runeAt(text string, pos int) (int, int) {
+	if pos >= len(text) {
+		return utf8.RuneError, 0
+	}
+
+	return utf8.DecodeRuneInString(text[pos:])
+}
+
+// Does text[pos:] start with something that can't continue a word?
+func boundaryAt(text string, pos int) bool {
+	r, size := runeAt(text, pos)
+
+	return size == 0 || !isWordRune(r)
+}
+
+func matchURL(text string, pos int) int {
+	for _, prefix := range urlPrefixes {
+		if pos+len(prefix) > len(text) || strings.ToLower(text[pos:pos+len(prefix)]) != prefix {
+			continue
+		}
+
+		end := pos + len(prefix)
+		for end < len(text) {
+			r, size := runeAt(text, end)
+			if unicode.IsSpace(r) || r == '<' || r == '>' || r == '"' {
+				break
+			}
+			end += size
+		}
+
+		// Trailing punctuation belongs to the sentence, not the URL
+		for end > pos+len(prefix) && strings.Index(".,;:!?'\")]}", text[end-1:end]) >= 0 {
+			end--
+		}
+
+		if end > pos+len(prefix) {
+			return end - pos
+		}
+	}
+
+	return 0
+}
+
+func matchEmoticon(text string, pos int) int {
+	for _, e := range emoticons {
+		if strings.HasPrefix(text[pos:], e) && boundaryAt(text, pos+len(e)) {
+			return len(e)
+		}
+	}
+
+	return 0
+}
+
+// Numbers, including decimals, thousands separators, times, fractions
+// and percentages (e.g. 3.14, 1,000, 12:30, 1/2, 50%)
+func matchNumber(text string, pos int) int {
+	end := pos
+	digits := func() {
+		for end < len(text) && text[end] >= '0' && text[end] <= '9' {
+			end++
+		}
+	}
+
+	digits()
+	if end == pos {
+		return 0
+	}
+
+	for end+1 < len(text) && strings.Index(".,:/", text[end:end+1]) >= 0 && text[end+1] >= '0' && text[end+1] <= '9' {
+		end++
+		digits()
+	}
+
+	if end < len(text) && text[end] == '%' {
+		end++
+	}
+
+	return end - pos
+}
+
+// Words, including internal apostrophes and hyphens (don't, well-known),
+// acronyms (U.S.) and known abbreviations (Mr.)
+func matchWord(text string, pos int) int {
+	end := pos
+	for end < len(text) {
+		r, size := runeAt(text, end)
+		if isWordRune(r) {
+			end += size
+			continue
+		}
+
+		// Keep going past an apostrophe or hyphen joining two words
+		if isApostrophe(r) || r == '-' {
+			if next, _ := runeAt(text, end+size); end > pos && isWordRune(next) {
+				end += size
+				continue
+			}
+		}
+
+		break
+	}
+
+	if end == pos || end >= len(text) || text[end] != '.' {
+		return end - pos
+	}
+
+	// Acronyms - single letters followed by periods
+	if acronym := pos; end-pos == 1 {
+		for acronym+1 < len(text) && isLetter(text[acronym]) && text[acronym+1] == '.' {
+			acronym += 2
+		}
+
+		if acronym-pos >= 4 {
+			return acronym - pos
+		}
+	}
+
+	// Abbreviations keep their period, unless it ends the text
+	next := strings.TrimSpace(text[end+1:])
+	if next != "" && isAbbreviation(strings.ToLower(text[pos:end]), next) {
+		return end + 1 - pos
+	}
+
+	return end - pos
+}
+
+func matchPunctuation(text string, pos int) int {
+	for _, p := range []string{"...", "--"} {
+		if strings.HasPrefix(text[pos:], p) {
+			end := pos + len(p)
+			for end < len(text) && text[end] == p[0] {
+				end++
+			}
+
+			return end - pos
+		}
+	}
+
+	_, size := runeAt(text, pos)
+	return size
+}
+
+// Split contractions and fused words as the Penn Treebank does
+// (don't -> do n't, we'll -> we 'll, cannot -> can not)
+func splitContraction(text string, start, end int) []Token {
+	word := strings.ToLower(text[start:end])
+
+	if split, ok := fusedWords[word]; ok {
+		return []Token{token(text, start, start+split), token(text, start+split, end)}
+	}
+
+	for _, suffix := range []string{"n't", "n’t"} {
+		if strings.HasSuffix(word, suffix) && len(word) > len(suffix) {
+			split := end - len(suffix)
+			return []Token{token(text, start, split), token(text, split, end)}
+		}
+	}
+
+	for _, suffix := range contractionSuffixes {
+		if strings.HasSuffix(word, suffix) && len(word) > len(suffix) {
+			split := end - len(suffix)
+			return []Token{token(text, start, split), token(text, split, end)}
+		}
+	}
+
+	return []Token{token(text, start, end)}
+}
+
+// A rule-based tokenizer following the Penn Treebank conventions:
+// punctuation is split off, contractions are split (do n't, John 's),
+// while URLs, numbers, emoticons, acronyms and common abbreviations
+// are kept whole. Unlike the original treebank script, token text is
+// never rewritten (quotes aren't converted to “ and ”) so that the
+// offsets stay meaningful.
+func Treebank(text string) []Token {
+	tokens := []Token{}
+
+	for pos := 0; pos < len(text); {
+		r, size := runeAt(text, pos)
+		if unicode.IsSpace(r) {
+			pos += size
+			continue
+		}
+
+		if n := matchURL(text, pos); n > 0 {
+			tokens = append(tokens, token(text, pos, pos+n))
+			pos += n
+		} else if n := matchEmoticon(text, pos); n > 0 {
+			tokens = append(tokens, token(text, pos, pos+n))
+			pos += n
+		} else if n := matchNumber(text, pos); n > 0 && boundaryAt(text, pos+n) {
+			tokens = append(tokens, token(text, pos, pos+n))
+			pos += n
+		} else if n := matchWord(text, pos); n > 0 {
+			tokens = append(tokens, splitContraction(text, pos, pos+n)...)
+			pos += n
+		} else {
+			n := matchPunctuation(text, pos)
+			tokens = append(tokens, token(text, pos, pos+n))
+			pos += n
+		}
+	}
+
+	return tokens
+}
+
+var _ Tokenizer = Whitespace
+var _ Tokenizer = Treebank
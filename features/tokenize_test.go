@@ -0,0 +1,48 @@
+package features
+
+import "regexp"
+import "strings"
+import "testing"
+
+func checkTokens(t *testing.T, name, text string, tokens []Token, expected string) {
+	if got := strings.Join(Strings(tokens), "|"); got != expected {
+		t.Errorf("%s(%q) = %s, expected %s", name, text, got, expected)
+	}
+
+	for _, tok := range tokens {
+		if text[tok.Start:tok.End] != tok.Text {
+			t.Errorf("%s(%q): token %q has offsets [%d, %d)", name, text, tok.Text, tok.Start, tok.End)
+		}
+	}
+}
+
+func TestWhitespace(t *testing.T) {
+	text := "  the\tcat \n sat "
+	checkTokens(t, "Whitespace", text, Whitespace(text), "the|cat|sat")
+}
+
+func TestRegexp(t *testing.T) {
+	text := "one, two; three"
+	checkTokens(t, "Regexp", text, Regexp(regexp.MustCompile("[a-z]+"))(text), "one|two|three")
+}
+
+func TestTreebank(t *testing.T) {
+	cases := map[string]string{
+		"I don't think they'll come.":           "I|do|n't|think|they|'ll|come|.",
+		"\"Hello,\" she said (quietly)!":        "\"|Hello|,|\"|she|said|(|quietly|)|!",
+		"You cannot go; it's John's.":           "You|can|not|go|;|it|'s|John|'s|.",
+		"Mr. Smith paid $1,000.50 on 12/25...":  "Mr.|Smith|paid|$|1,000.50|on|12/25|...",
+		"See http://example.com/a?b=1, ok?":     "See|http://example.com/a?b=1|,|ok|?",
+		"It rose 5% in the U.S. market :-) <3":  "It|rose|5%|in|the|U.S.|market|:-)|<3",
+		"a well-known 3rd-party tool -- really": "a|well-known|3rd-party|tool|--|really",
+		"Ask Dr.":                               "Ask|Dr|.",
+		"The answer is no. We left.":            "The|answer|is|no|.|We|left|.",
+		"See No. 5 in Dec. or Sep.":             "See|No.|5|in|Dec|.|or|Sep|.",
+		"It opens Dec. 5 at noon":               "It|opens|Dec.|5|at|noon",
+		"Acme Co. Ltd is hiring":                "Acme|Co.|Ltd|is|hiring",
+	}
+
+	for text, expected := range cases {
+		checkTokens(t, "Treebank", text, Treebank(text), expected)
+	}
+}
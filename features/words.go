@@ -1,20 +1,147 @@
 package features
 
+import "bytes"
 import "fmt"
-import "strings"
 
+// N-gram keys are words joined by NGramSeparator, with any separators
+// (or escapes) inside the words escaped by a backslash. Keys made of
+// plain words are the same as the words joined with WordCombine.
+const NGramSeparator = ' '
+
+const ngramEscape = '\\'
+
+// Combine two n-gram keys into a longer one
 func WordCombine(a, b string) string {
 
 	return fmt.Sprintf("%s %s", a, b)
 }
 
+// Split the first word off of an n-gram key, returning ("", w) if w
+// is a single word
 func WordSplit(w string) (string, string) {
-	result := strings.SplitN(w, " ", 1)
+	head, tail := NGramSplit(w, 1)
 
-	if len(result) == 1 {
+	if tail == "" {
 		return "", w
 	}
 
-	return result[0], result[1]
+	return head, tail
+}
+
+// Find the byte offsets of the unescaped separators in key
+func separators(key string) []int {
+	result := []int{}
+
+	for i := 0; i < len(key); i++ {
+		switch key[i] {
+		case ngramEscape:
+			i++
+		case NGramSeparator:
+			result = append(result, i)
+		}
+	}
+
+	return result
 }
 
+// Encode a list of words as an n-gram key
+func NGramKey(words []string) string {
+	buf := new(bytes.Buffer)
+
+	for idx, w := range words {
+		if idx > 0 {
+			buf.WriteByte(NGramSeparator)
+		}
+
+		for i := 0; i < len(w); i++ {
+			if w[i] == NGramSeparator || w[i] == ngramEscape {
+				buf.WriteByte(ngramEscape)
+			}
+			buf.WriteByte(w[i])
+		}
+	}
+
+	return buf.String()
+}
+
+// Decode an n-gram key back into its words
+func NGramWords(key string) []string {
+	words := []string{}
+	if key == "" {
+		return words
+	}
+
+	word := new(bytes.Buffer)
+
+	for i := 0; i < len(key); i++ {
+		switch {
+		case key[i] == ngramEscape && i+1 < len(key):
+			i++
+			word.WriteByte(key[i])
+		case key[i] == NGramSeparator:
+			words = append(words, word.String())
+			word.Reset()
+		default:
+			word.WriteByte(key[i])
+		}
+	}
+
+	return append(words, word.String())
+}
+
+// Split an n-gram key after its first n words, returning the two
+// halves as keys. Negative values of n split off the last -n words,
+// so NGramSplit(key, -1) separates a history from the word it
+// predicts.
+func NGramSplit(key string, n int) (string, string) {
+	seps := separators(key)
+
+	if n < 0 {
+		n += len(seps) + 1
+	}
+
+	switch {
+	case n <= 0:
+		return "", key
+	case n > len(seps):
+		return key, ""
+	}
+
+	return key[:seps[n-1]], key[seps[n-1]+1:]
+}
+
+// Every n-gram of tokens for n from 1 to max, encoded as keys
+func NGrams(tokens []string, max int) []string {
+	return SkipGrams(tokens, max, 0)
+}
+
+// Every k-skip-n-gram of tokens for n from 1 to max: n-grams whose
+// words may skip over up to skip tokens in total (so with skip = 0
+// these are ordinary n-grams). Results are ordered by n, then by
+// position.
+func SkipGrams(tokens []string, max, skip int) []string {
+	result := []string{}
+
+	var extend func(words []string, last, skipped, n int)
+	extend = func(words []string, last, skipped, n int) {
+		if len(words) == n {
+			result = append(result, NGramKey(words))
+			return
+		}
+
+		for next := last + 1; next < len(tokens) && skipped+next-last-1 <= skip; next++ {
+			extend(append(words, tokens[next]), next, skipped+next-last-1, n)
+		}
+	}
+
+	for n := 1; n <= max; n++ {
+		for start := 0; start < len(tokens); start++ {
+			words := make([]string, 1, n)
+			words[0] = tokens[start]
+
+			extend(words, start, 0, n)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,43 @@
+package features
+
+import "strings"
+import "testing"
+
+func TestWordSplit(t *testing.T) {
+	if a, b := WordSplit(WordCombine("new", "york city")); a != "new" || b != "york city" {
+		t.Errorf("WordSplit split off (%q, %q)", a, b)
+	}
+
+	if a, b := WordSplit("york"); a != "" || b != "york" {
+		t.Errorf("WordSplit of a single word gave (%q, %q)", a, b)
+	}
+}
+
+func TestNGramKey(t *testing.T) {
+	words := []string{"a b", "c\\", "", "d"}
+	key := NGramKey(words)
+
+	if decoded := NGramWords(key); strings.Join(decoded, "|") != strings.Join(words, "|") || len(decoded) != len(words) {
+		t.Errorf("NGramWords(NGramKey(%q)) = %q", words, decoded)
+	}
+
+	if history, word := NGramSplit(key, -1); word != "d" || NGramKey(NGramWords(history)) != NGramKey(words[:3]) {
+		t.Errorf("NGramSplit(%q, -1) = (%q, %q)", key, history, word)
+	}
+
+	if head, tail := NGramSplit(key, 1); head != "a\\ b" || len(NGramWords(tail)) != 3 {
+		t.Errorf("NGramSplit(%q, 1) = (%q, %q)", key, head, tail)
+	}
+}
+
+func TestSkipGrams(t *testing.T) {
+	tokens := strings.Fields("a b c d")
+
+	if grams := strings.Join(NGrams(tokens, 2), "|"); grams != "a|b|c|d|a b|b c|c d" {
+		t.Errorf("NGrams = %s", grams)
+	}
+
+	if grams := strings.Join(SkipGrams(tokens, 3, 1), "|"); !strings.HasSuffix(grams, "|a b c|a b d|a c d|b c d") {
+		t.Errorf("SkipGrams = %s", grams)
+	}
+}
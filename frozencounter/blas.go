@@ -68,3 +68,25 @@ func (v vector) scale(a float64) {
 func (v vector) argmax() int {
 	return int(C.cblas_idamax(C.int(len(v)), (*C.double)(unsafe.Pointer(&v[0])), 1))
 }
+
+// y = alpha * A x + beta * y, where A is a rows x cols row-major matrix
+// (or y = alpha * A' x + beta * y if trans)
+func gemv(trans bool, rows, cols int, alpha float64, a, x vector, beta float64, y vector) {
+	var t C.enum_CBLAS_TRANSPOSE = C.CblasNoTrans
+	if trans {
+		t = C.CblasTrans
+	}
+
+	C.cblas_dgemv(C.CblasRowMajor, t, C.int(rows), C.int(cols), C.double(alpha), (*C.double)(unsafe.Pointer(&a[0])), C.int(cols), (*C.double)(unsafe.Pointer(&x[0])), 1, C.double(beta), (*C.double)(unsafe.Pointer(&y[0])), 1)
+}
+
+// c = alpha * A B' + beta * c, where A is m x k, B is n x k and c is
+// m x n, all row-major
+func gemmTransposed(m, n, k int, alpha float64, a, b vector, beta float64, c vector) {
+	C.cblas_dgemm(C.CblasRowMajor, C.CblasNoTrans, C.CblasTrans, C.int(m), C.int(n), C.int(k), C.double(alpha), (*C.double)(unsafe.Pointer(&a[0])), C.int(k), (*C.double)(unsafe.Pointer(&b[0])), C.int(k), C.double(beta), (*C.double)(unsafe.Pointer(&c[0])), C.int(n))
+}
+
+// A += alpha * x y', where A is a rows x cols row-major matrix
+func ger(rows, cols int, alpha float64, x, y, a vector) {
+	C.cblas_dger(C.CblasRowMajor, C.int(rows), C.int(cols), C.double(alpha), (*C.double)(unsafe.Pointer(&x[0])), 1, (*C.double)(unsafe.Pointer(&y[0])), 1, (*C.double)(unsafe.Pointer(&a[0])), C.int(cols))
+}
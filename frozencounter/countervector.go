@@ -2,44 +2,72 @@ package frozencounter
 
 import "gnlp/minimizer"
 import "fmt"
+import "os"
+import "sort"
 
-// A countervector stores counters indexed by strings
+// A countervector stores counters indexed by strings - a matrix with a
+// row per key in Keys, each a counter over SubKeys, stored row after
+// row in a single vector
 type CounterVector struct {
-	Keys *KeySet
+	Keys    *KeySet
 	SubKeys *KeySet
-	size int
+	// The length of each row
+	size   int
 	values vector
 }
 
-func NewCounterVector(counters map[string]*Counter) *CounterVector {
+// A counter vector of zeros with a row per key in keys, each over
+// subKeys
+func NewCounterVectorWithKeySets(keys, subKeys *KeySet) *CounterVector {
+	size := subKeys.Len()
+
+	return &CounterVector{Keys: keys, SubKeys: subKeys, size: size, values: make(vector, keys.Len()*size)}
+}
+
+// Build a counter vector from a map of counters, which must share a
+// keyset. Rows are in sorted key order.
+func NewCounterVector(counters map[string]*Counter) (*CounterVector, os.Error) {
+	if len(counters) == 0 {
+		return nil, os.NewError("countervector: no counters")
+	}
+
 	var subks *KeySet = nil
 
-	keys := []string{}
+	keys := make([]string, 0, len(counters))
 	for key, c := range counters {
-		subks = c.Keys
+		if c == nil {
+			return nil, fmt.Errorf("countervector: nil counter for %q", key)
+		}
+
+		if subks == nil {
+			subks = c.Keys
+		} else if c.Keys != subks {
+			return nil, fmt.Errorf("countervector: counter for %q has a different keyset", key)
+		}
+
 		keys = append(keys, key)
 	}
 
-	ks := NewKeySet(keys, 0.0)
-	size := len(subks.Keys)
+	sort.Strings(keys)
+	cv := NewCounterVectorWithKeySets(NewKeySet(keys, 0.0), subks)
 
-	vals := make(vector, size * len(keys))
-	for pos, key := range ks.Keys {
-		copy(vals[pos:pos+size], counters[key].values)
+	for pos, key := range keys {
+		copy(cv.values[pos*cv.size:(pos+1)*cv.size], counters[key].values)
 	}
 
-	return &CounterVector{Keys: ks, SubKeys: subks, size: size, values: vals}
+	return cv, nil
 }
 
 // Copy the shape (but not the values) of cv
 func (cv *CounterVector) Clone() *CounterVector {
-	return &CounterVector{Keys: cv.Keys, SubKeys: cv.SubKeys, size: cv.size, values: make(vector, len(cv.values))}
+	return NewCounterVectorWithKeySets(cv.Keys, cv.SubKeys)
 }
 
+// Every row, keyed by Keys. The counters are views of cv, as with Get.
 func (cv *CounterVector) Extract() map[string]*Counter {
 	result := make(map[string]*Counter)
-	for _, key := range cv.Keys.Keys {
-		result[key] = cv.Get(key)
+	for pos := 0; pos < cv.rows(); pos++ {
+		result[cv.Keys.Key(pos)] = cv.row(pos)
 	}
 
 	return result
@@ -48,29 +76,45 @@ func (cv *CounterVector) Extract() map[string]*Counter {
 func (cv *CounterVector) String() string {
 	result := "CounterVector{"
 
-	for _, label := range cv.Keys.Keys {
-		result += fmt.Sprintf("[%s]: %s ", label, cv.Get(label))
+	for pos := 0; pos < cv.rows(); pos++ {
+		result += fmt.Sprintf("[%s]: %s ", cv.Keys.Key(pos), cv.row(pos))
 	}
 
 	return result + "}"
 }
 
+// The row for key, as a view of cv - changing its values changes cv's.
+// Panics if key isn't in Keys.
 func (cv *CounterVector) Get(key string) *Counter {
-	pos, ok := cv.Keys.Positions[key]
+	pos, ok := cv.Keys.Position(key)
 	if !ok {
 		panic("Key missing")
 	}
 
-	return &Counter{Keys: cv.SubKeys, values: cv.values[pos*cv.size:(pos+1)*cv.size]}
+	return cv.row(pos)
 }
 
-func (cv *CounterVector) Set(key string, c Counter) {
-	pos, ok := c.Keys.Positions[key]
+// Copy c's values into the row for key
+func (cv *CounterVector) Set(key string, c *Counter) os.Error {
+	pos, ok := cv.Keys.Position(key)
 	if !ok {
-		panic("Key missing")
+		return fmt.Errorf("countervector: no row for %q", key)
+	}
+
+	if c.Keys != cv.SubKeys {
+		return fmt.Errorf("countervector: counter for %q has a different keyset", key)
 	}
 
-	copy(c.values[pos*cv.size:(pos+1)*cv.size], c.values)
+	copy(cv.values[pos*cv.size:(pos+1)*cv.size], c.values)
+	return nil
+}
+
+// Call op with every row (as a view of cv, so op may update it), in
+// order
+func (cv *CounterVector) ApplyRows(op func(key string, row *Counter)) {
+	for pos := 0; pos < cv.rows(); pos++ {
+		op(cv.Keys.Key(pos), cv.row(pos))
+	}
 }
 
 func (c *CounterVector) Reset(v float64) {
@@ -112,4 +156,3 @@ func (cv *CounterVector) DotProduct(o minimizer.Vector) float64 {
 
 	return cv.values.dot(o.(*CounterVector).values)
 }
-
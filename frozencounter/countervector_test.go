@@ -0,0 +1,137 @@
+package frozencounter
+
+import "testing"
+
+func vectorRows(features *KeySet) map[string]*Counter {
+	rows := map[string]*Counter{"cv-b": New(features), "cv-a": New(features), "cv-c": New(features)}
+	for i, label := range []string{"cv-a", "cv-b", "cv-c"} {
+		for j, f := range features.Keys {
+			rows[label].Set(f, float64(10*i+j))
+		}
+	}
+
+	return rows
+}
+
+func TestCounterVectorLayout(t *testing.T) {
+	features := NewKeySet([]string{"cv-f1", "cv-f2"}, 0.0)
+	rows := vectorRows(features)
+
+	cv, err := NewCounterVector(rows)
+	if err != nil {
+		t.Fatalf("NewCounterVector failed: %s", err)
+	}
+
+	if cv.Keys.Len() != 3 || cv.Keys.Key(0) != "cv-a" || cv.Keys.Key(2) != "cv-c" || cv.SubKeys != features {
+		t.Fatalf("Bad keysets %v %v", cv.Keys.Keys, cv.SubKeys.Keys)
+	}
+
+	for label, row := range rows {
+		for _, f := range features.Keys {
+			if got := cv.Get(label).Get(f); got != row.Get(f) {
+				t.Errorf("[%s][%s] = %f, expected %f", label, f, got, row.Get(f))
+			}
+		}
+	}
+
+	// The vector holds copies of the rows
+	rows["cv-a"].Set("cv-f1", 100.0)
+	if cv.Get("cv-a").Get("cv-f1") != 0 {
+		t.Errorf("Vector shares storage with its input")
+	}
+}
+
+func TestCounterVectorErrors(t *testing.T) {
+	if _, err := NewCounterVector(map[string]*Counter{}); err == nil {
+		t.Errorf("Expected an error for no counters")
+	}
+
+	a := New(NewKeySet([]string{"cv-x"}, 0.0))
+	b := New(NewKeySet([]string{"cv-y"}, 0.0))
+	if _, err := NewCounterVector(map[string]*Counter{"a": a, "b": b}); err == nil {
+		t.Errorf("Expected an error for mismatched keysets")
+	}
+
+	if _, err := NewCounterVector(map[string]*Counter{"a": a, "b": nil}); err == nil {
+		t.Errorf("Expected an error for a nil counter")
+	}
+
+	cv, _ := NewCounterVector(map[string]*Counter{"a": a})
+	if err := cv.Set("a", b); err == nil {
+		t.Errorf("Expected an error setting a row with the wrong keyset")
+	}
+	if err := cv.Set("missing", a); err == nil {
+		t.Errorf("Expected an error setting a missing row")
+	}
+}
+
+func TestCounterVectorRows(t *testing.T) {
+	features := NewKeySet([]string{"cv-f1", "cv-f2"}, 0.0)
+	cv := NewCounterVectorWithKeySets(NewKeySet([]string{"cv-r1", "cv-r2"}, 0.0), features)
+
+	row := New(features)
+	row.Set("cv-f2", 3.0)
+	if err := cv.Set("cv-r2", row); err != nil {
+		t.Fatalf("Set failed: %s", err)
+	}
+
+	if cv.Get("cv-r1").Get("cv-f2") != 0 || cv.Get("cv-r2").Get("cv-f2") != 3 {
+		t.Errorf("Set wrote the wrong row: %s", cv)
+	}
+
+	// Rows are views
+	cv.Get("cv-r1").Set("cv-f1", 2.0)
+	if cv.Extract()["cv-r1"].Get("cv-f1") != 2 {
+		t.Errorf("Row isn't a view: %s", cv)
+	}
+
+	seen := []string{}
+	cv.ApplyRows(func(key string, row *Counter) {
+		seen = append(seen, key)
+		row.Set("cv-f1", row.Get("cv-f1")+1.0)
+	})
+
+	if len(seen) != 2 || seen[0] != "cv-r1" || seen[1] != "cv-r2" {
+		t.Errorf("ApplyRows visited %v", seen)
+	}
+	if cv.Get("cv-r1").Get("cv-f1") != 3 || cv.Get("cv-r2").Get("cv-f1") != 1 || cv.Get("cv-r2").Get("cv-f2") != 3 {
+		t.Errorf("Bad ApplyRows result %s", cv)
+	}
+}
+
+func TestCounterVectorArithmetic(t *testing.T) {
+	features := NewKeySet([]string{"cv-f1", "cv-f2"}, 0.0)
+	cv, _ := NewCounterVector(vectorRows(features))
+
+	other := cv.Copy().(*CounterVector)
+	other.Scale(2.0)
+	if cv.Get("cv-c").Get("cv-f2") != 21 || other.Get("cv-c").Get("cv-f2") != 42 {
+		t.Fatalf("Copy shares storage")
+	}
+
+	// 0 + 1 + 100 + 121 + 400 + 441
+	if dot := cv.DotProduct(cv); dot != 1063 {
+		t.Errorf("DotProduct = %f, expected 1063", dot)
+	}
+
+	other.Subtract(cv)
+	if other.DotProduct(cv) != 1063 {
+		t.Errorf("Bad Subtract %s", other)
+	}
+
+	other.Negate()
+	other.AddScaled(1.0, cv)
+	if other.DotProduct(other) != 0 {
+		t.Errorf("Bad Negate/AddScaled %s", other)
+	}
+
+	clone := cv.Clone()
+	if clone.Keys != cv.Keys || clone.SubKeys != cv.SubKeys || clone.DotProduct(clone) != 0 {
+		t.Errorf("Bad Clone %s", clone)
+	}
+
+	clone.Reset(1.0)
+	if clone.Get("cv-b").Get("cv-f2") != 1 {
+		t.Errorf("Bad Reset %s", clone)
+	}
+}
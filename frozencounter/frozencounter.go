@@ -11,7 +11,7 @@ type Counter struct {
 }
 
 func New(ks *KeySet) *Counter {
-	v := make(vector, len(ks.Keys))
+	v := make(vector, ks.Len())
 	v.reset(ks.Base)
 
 	return &Counter{ks, v}
@@ -24,7 +24,17 @@ func (c *Counter) Reset(v float64) {
 // Freeze a counter, using a previously-generated keyset and
 // index.
 func FreezeWithKeySet(c *counter.Counter, ks *KeySet) *Counter {
-	values := make([]float64, len(ks.Keys))
+	values := make([]float64, ks.Len())
+
+	if ks.hashed() {
+		// Colliding keys share a bucket
+		for _, s := range c.Keys() {
+			idx, _ := ks.Position(s)
+			values[idx] += ks.Sign(s) * c.Get(s)
+		}
+
+		return &Counter{ks, values}
+	}
 
 	for s, idx := range ks.Positions {
 		values[idx] = c.Get(s)
@@ -72,6 +82,16 @@ func FreezeMany(counters []*counter.Counter) []*Counter {
 	return results
 }
 
+// Freeze a map of counters using a previously-generated keyset
+func FreezeMapWithKeySet(counters map[string]*counter.Counter, ks *KeySet) map[string]*Counter {
+	frozen := make(map[string]*Counter)
+	for k, c := range counters {
+		frozen[k] = FreezeWithKeySet(c, ks)
+	}
+
+	return frozen
+}
+
 func FreezeMap(counters map[string]*counter.Counter) map[string]*Counter {
 	order := make([]string, 0, len(counters))
 	for k, _ := range counters {
@@ -94,41 +114,41 @@ func FreezeMap(counters map[string]*counter.Counter) map[string]*Counter {
 }
 
 func (c *Counter) Get(f string) float64 {
-	idx, ok := c.Keys.Positions[f]
+	idx, ok := c.Keys.Position(f)
 
 	if !ok {
 		return c.Keys.Base
 	}
 
-	return c.values[idx]
+	return c.Keys.Sign(f) * c.values[idx]
 }
 
 func (c *Counter) Set(f string, val float64) {
-	idx, ok := c.Keys.Positions[f]
+	idx, ok := c.Keys.Position(f)
 
 	if !ok {
 		panic("Feature not found in frozen counter")
 	}
 
-	c.values[idx] = val
+	c.values[idx] = c.Keys.Sign(f) * val
 }
 
 func (c *Counter) Incr(f string) {
-	idx, ok := c.Keys.Positions[f]
+	idx, ok := c.Keys.Position(f)
 
 	if !ok {
 		panic("Feature not found in frozen counter")
 	}
 
-	c.values[idx] += 1
+	c.values[idx] += c.Keys.Sign(f)
 }
 
 // Convert a frozen counter back into a counter.Counter.
 func (c *Counter) Thaw() *counter.Counter {
 	t := counter.New(c.Keys.Base)
 
-	for s, idx := range c.Keys.Positions {
-		t.Set(s, c.values[idx])
+	for idx, v := range c.values {
+		t.Set(c.Keys.Key(idx), v)
 	}
 
 	return t
@@ -141,8 +161,8 @@ func (c *Counter) Copy() *Counter {
 func (c *Counter) String() string {
 	s := "FrozenCounter: {"
 
-	for idx, key := range c.Keys.Keys {
-		s += fmt.Sprintf("'%s': %f, ", key, c.values[idx])
+	for idx, v := range c.values {
+		s += fmt.Sprintf("'%s': %f, ", c.Keys.Key(idx), v)
 	}
 
 	s += "}"
@@ -153,7 +173,7 @@ func (c *Counter) String() string {
 func (c *Counter) ArgMax() (string, float64) {
 	idx := c.values.argmax()
 
-	return c.Keys.Keys[idx], c.values[idx]
+	return c.Keys.Key(idx), c.values[idx]
 }
 
 func (c *Counter) check(o *Counter) {
@@ -264,7 +284,8 @@ func (c *Counter) DotProduct(o *Counter) float64 {
 // Apply a function to every value in the counter
 func (c *Counter) Apply(op func(f *string, a float64) float64) {
 	for idx, v := range c.values {
-		c.values[idx] = op(&c.Keys.Keys[idx], v)
+		key := c.Keys.Key(idx)
+		c.values[idx] = op(&key, v)
 	}
 }
 
@@ -298,4 +319,65 @@ func (c *Counter) LogNormalize() {
 	c.Apply(func(f *string, a float64) float64 { return math.Log(a) - logSum })
 }
 
+// ln(a + b), given ln(a) and ln(b)
+func logAdd(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+	if math.IsInf(b, -1) {
+		return a
+	}
+	if a < b {
+		a, b = b, a
+	}
+
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+// ln(sum(exp(v))) over the values in the counter, computed without
+// overflow
+func (c *Counter) LogSumExp() float64 {
+	max := math.Inf(-1)
+	for _, v := range c.values {
+		if v > max {
+			max = v
+		}
+	}
+
+	if math.IsInf(max, 0) {
+		return max
+	}
+
+	sum := 0.0
+	for _, v := range c.values {
+		sum += math.Exp(v - max)
+	}
+
+	return max + math.Log(sum)
+}
+
+// Normalize a log-distribution s.t. the sum over exp(values) is now
+// 1.0, without leaving log space
+func (c *Counter) LogRenormalize() {
+	logSum := c.LogSumExp()
+
+	for idx, v := range c.values {
+		c.values[idx] = v - logSum
+	}
+}
+
+// Add o to c in log space, i.e. ln(exp(c) + exp(o))
+func (c *Counter) LogAdd(o *Counter) {
+	c.operate(o, logAdd)
+}
+
+// Add a to b in log space, returning a new counter
+func LogAdd(a, b *Counter) *Counter {
+	a.check(b)
+
+	result := a.Copy()
+	result.LogAdd(b)
+	return result
+}
+
 var _ gnlp.Counter = new(Counter)
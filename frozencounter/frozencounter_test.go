@@ -0,0 +1,30 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "math"
+import "testing"
+
+func TestLogSpace(t *testing.T) {
+	a, b := counter.New(0.0), counter.New(0.0)
+	a.Set("x", 1000.0)
+	a.Set("y", 1000.0+math.Log(3.0))
+	b.Set("x", math.Log(0.5))
+	b.Set("y", math.Log(0.5))
+
+	frozen := FreezeMany([]*counter.Counter{a, b})
+	fa, fb := frozen[0], frozen[1]
+
+	if lse := fa.LogSumExp(); math.Abs(lse-(1000.0+math.Log(4.0))) > 1e-9 {
+		t.Errorf("LogSumExp = %f", lse)
+	}
+
+	fa.LogRenormalize()
+	if p := math.Exp(fa.Get("y")); math.Abs(p-0.75) > 1e-12 {
+		t.Errorf("P(y) = %f, expected 0.75", p)
+	}
+
+	sum := LogAdd(fa, fb)
+	if math.Abs(sum.Get("x")-math.Log(0.75)) > 1e-12 || math.Abs(sum.LogSumExp()-math.Log(2.0)) > 1e-12 {
+		t.Errorf("Bad log sum %s", sum)
+	}
+}
@@ -0,0 +1,155 @@
+package frozencounter
+
+import "math"
+
+// Information theoretic measures over frozen distributions, in nats.
+// As in the counter package, zero probability terms contribute nothing
+// and the Log variants take counters of log probabilities. The sums are
+// computed as BLAS dot products.
+
+// ln(v), with 0 wherever mask is 0 so that those terms drop out of a
+// dot product with mask
+func maskedLog(v, mask vector) vector {
+	result := make(vector, len(v))
+	for i, x := range v {
+		if mask[i] != 0 {
+			result[i] = math.Log(x)
+		}
+	}
+
+	return result
+}
+
+// exp(v), e.g. probabilities from log probabilities
+func exp(v vector) vector {
+	result := make(vector, len(v))
+	for i, x := range v {
+		result[i] = math.Exp(x)
+	}
+
+	return result
+}
+
+func entropy(p vector) float64 {
+	return -p.dot(maskedLog(p, p))
+}
+
+func crossEntropy(p, q vector) float64 {
+	return -p.dot(maskedLog(q, p))
+}
+
+func kl(p, q vector) float64 {
+	return p.dot(maskedLog(p, p)) - p.dot(maskedLog(q, p))
+}
+
+func js(p, q vector) float64 {
+	m := p.copy()
+	m.add(q)
+	m.scale(0.5)
+
+	return 0.5*kl(p, m) + 0.5*kl(q, m)
+}
+
+// The entropy of p, H(p) = -sum p ln(p)
+func Entropy(p *Counter) float64 {
+	return entropy(p.values)
+}
+
+// The entropy of a log distribution
+func LogEntropy(p *Counter) float64 {
+	return entropy(exp(p.values))
+}
+
+// The cross entropy of q relative to p, H(p, q) = -sum p ln(q)
+func CrossEntropy(p, q *Counter) float64 {
+	p.check(q)
+
+	return crossEntropy(p.values, q.values)
+}
+
+// The cross entropy of log distributions
+func LogCrossEntropy(p, q *Counter) float64 {
+	p.check(q)
+
+	return crossEntropy(exp(p.values), exp(q.values))
+}
+
+// The Kullback-Leibler divergence of q from p, sum p ln(p / q). This is
+// +Inf if q is 0 anywhere p isn't.
+func KL(p, q *Counter) float64 {
+	p.check(q)
+
+	return kl(p.values, q.values)
+}
+
+// The KL divergence of log distributions
+func LogKL(p, q *Counter) float64 {
+	p.check(q)
+
+	return kl(exp(p.values), exp(q.values))
+}
+
+// The Jensen-Shannon divergence of p and q - the mean of their KL
+// divergences from (p + q) / 2
+func JS(p, q *Counter) float64 {
+	p.check(q)
+
+	return js(p.values, q.values)
+}
+
+// The JS divergence of log distributions
+func LogJS(p, q *Counter) float64 {
+	p.check(q)
+
+	return js(exp(p.values), exp(q.values))
+}
+
+// The mutual information between the keys of joint and those of its
+// counters, given joint counts or probabilities (which needn't be
+// normalized). The counters must share a keyset. An empty joint, or one
+// with no mass, has no mutual information.
+func MutualInformation(joint map[string]*Counter) float64 {
+	var first *Counter
+	var cols vector
+	total := 0.0
+
+	for _, row := range joint {
+		if first == nil {
+			first = row
+			cols = make(vector, len(row.values))
+		}
+		first.check(row)
+
+		cols.add(row.values)
+		total += row.values.sum()
+	}
+
+	if total == 0 {
+		return 0.0
+	}
+
+	// sum n(x, y) (ln n(x, y) - ln n(x) - ln n(y)) / N + ln N
+	logCols := maskedLog(cols, cols)
+	mi := 0.0
+
+	for _, row := range joint {
+		n := row.values.sum()
+		if n == 0 {
+			continue
+		}
+
+		mi += row.values.dot(maskedLog(row.values, row.values)) - n*math.Log(n) - row.values.dot(logCols)
+	}
+
+	return mi/total + math.Log(total)
+}
+
+// The mutual information given joint log probabilities
+func LogMutualInformation(joint map[string]*Counter) float64 {
+	probs := make(map[string]*Counter)
+	for k, row := range joint {
+		probs[k] = &Counter{row.Keys, exp(row.values)}
+	}
+
+	return MutualInformation(probs)
+}
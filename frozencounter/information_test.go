@@ -0,0 +1,74 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "math"
+import "testing"
+
+func TestInformation(t *testing.T) {
+	p, q := counter.New(0.0), counter.New(0.0)
+	p.Set("a", 0.5)
+	p.Set("b", 0.5)
+	q.Set("a", 0.25)
+	q.Set("b", 0.25)
+	q.Set("c", 0.5)
+
+	frozen := FreezeMany([]*counter.Counter{p, q})
+	fp, fq := frozen[0], frozen[1]
+	lp, lq := fp.Copy(), fq.Copy()
+	lp.Log()
+	lq.Log()
+
+	tests := []struct {
+		name          string
+		value, expect float64
+	}{
+		{"H(p)", Entropy(fp), counter.Entropy(p)},
+		{"H(p, q)", CrossEntropy(fp, fq), counter.CrossEntropy(p, q)},
+		{"KL(p || q)", KL(fp, fq), counter.KL(p, q)},
+		{"JS(p, q)", JS(fp, fq), counter.JS(p, q)},
+		{"log H(q)", LogEntropy(lq), counter.Entropy(q)},
+		{"log H(p, q)", LogCrossEntropy(lp, lq), counter.CrossEntropy(p, q)},
+		{"log KL(p || q)", LogKL(lp, lq), counter.KL(p, q)},
+		{"log JS(p, q)", LogJS(lp, lq), counter.JS(p, q)},
+	}
+
+	for _, test := range tests {
+		if math.Abs(test.value-test.expect) > 1e-12 {
+			t.Errorf("%s = %f, expected %f", test.name, test.value, test.expect)
+		}
+	}
+
+	if kl := KL(fq, fp); !math.IsInf(kl, 1) {
+		t.Errorf("KL(q || p) = %f, expected +Inf", kl)
+	}
+}
+
+func TestMutualInformation(t *testing.T) {
+	a, b := counter.New(0.0), counter.New(0.0)
+	a.Set("c", 3.0)
+	a.Set("d", 1.0)
+	b.Set("c", 1.0)
+	b.Set("d", 3.0)
+
+	joint := counter.NewConditional(0.0)
+	joint.Set("a", "c", 3.0)
+	joint.Set("a", "d", 1.0)
+	joint.Set("b", "c", 1.0)
+	joint.Set("b", "d", 3.0)
+
+	frozen := FreezeMap(map[string]*counter.Counter{"a": a, "b": b})
+	if mi, expect := MutualInformation(frozen), counter.MutualInformation(joint); math.Abs(mi-expect) > 1e-12 {
+		t.Errorf("MI = %f, expected %f", mi, expect)
+	}
+}
+
+func TestMutualInformationNoMass(t *testing.T) {
+	if mi := MutualInformation(map[string]*Counter{}); mi != 0 {
+		t.Errorf("MI of an empty joint = %f, expected 0", mi)
+	}
+
+	ks := NewKeySet([]string{"empty-c", "empty-d"}, 0.0)
+	if mi := MutualInformation(map[string]*Counter{"a": New(ks), "b": New(ks)}); mi != 0 {
+		t.Errorf("MI of a zero joint = %f, expected 0", mi)
+	}
+}
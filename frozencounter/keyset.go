@@ -1,61 +1,132 @@
 package frozencounter
 
 import crc "hash/crc64"
+import "fmt"
+import "hash/fnv"
+import "strconv"
+import "sync"
 
 type KeySet struct {
 	Keys      []string
 	Positions map[string]int
 	Hash      uint64
 	Base      float64
+
+	// Hashed keysets (see NewHashedKeySet) store no Keys or Positions;
+	// keys are hashed into 1 << Bits buckets instead
+	Bits   uint
+	Signed bool
+}
+
+// An interning scope for keysets. Keysets built through the same
+// interner with the same keys (in the same order) and base are the same
+// instance, so counters over them are compatible. Safe for concurrent
+// use.
+type Interner struct {
+	sync.Mutex
+	// Keysets by hash
+	cache map[uint64][]*KeySet
 }
 
-var keySetCache map[uint64][]*KeySet
+// The interner used by NewKeySet, NewHashedKeySet and the Freeze
+// functions
+var DefaultInterner = NewInterner()
 
-func init() {
-	// Build the interning cache
-	keySetCache = make(map[uint64][]*KeySet)
+func NewInterner() *Interner {
+	return &Interner{cache: make(map[uint64][]*KeySet)}
 }
 
-func internKeySet(ks *KeySet) *KeySet {
-	possibles, ok := keySetCache[ks.Hash]
+func sameKeySet(a, b *KeySet) bool {
+	if a.Base != b.Base || a.Bits != b.Bits || a.Signed != b.Signed {
+		return false
+	}
+
+	if len(a.Keys) != len(b.Keys) {
+		return false
+	}
 
-	// We found something with this hash
-	if ok {
-		// Look for it
-		for _, possible := range possibles {
-			// If the keys match up, this is it - return possible as
-			// the canonical instance
-			if possible.Base != ks.Base {
-				continue
-			}
+	for idx, v := range a.Keys {
+		if b.Keys[idx] != v {
+			return false
+		}
+	}
 
-			if len(possible.Keys) != len(ks.Keys) {
-				continue
-			}
+	return true
+}
 
-			for idx, v := range possible.Keys {
-				if ks.Keys[idx] != v {
-					continue
-				}
-			}
+// Return the canonical instance of ks, making ks canonical if there
+// isn't one yet
+func (in *Interner) Intern(ks *KeySet) *KeySet {
+	in.Lock()
+	defer in.Unlock()
 
+	for _, possible := range in.cache[ks.Hash] {
+		if sameKeySet(possible, ks) {
 			return possible
 		}
-	} else {
-		// This bucket is empty, initialize it to a list
-		keySetCache[ks.Hash] = make([]*KeySet, 0, 1)
 	}
 
-	// Put this keyset in the bucket as the canonical instance
-	keySetCache[ks.Hash] = append(keySetCache[ks.Hash], ks)
-
+	in.cache[ks.Hash] = append(in.cache[ks.Hash], ks)
 	return ks
+}
+
+// Drop ks from the interner so it can be garbage collected once no
+// counters use it. Counters over ks keep working, but keysets built
+// afterwards with the same keys won't be compatible with them.
+func (in *Interner) Release(ks *KeySet) {
+	in.Lock()
+	defer in.Unlock()
+
+	possibles := in.cache[ks.Hash]
+	for idx, possible := range possibles {
+		if possible != ks {
+			continue
+		}
+
+		if len(possibles) == 1 {
+			in.cache[ks.Hash] = nil, false
+		} else {
+			in.cache[ks.Hash] = append(possibles[:idx], possibles[idx+1:]...)
+		}
+		return
+	}
+}
+
+// Release every keyset
+func (in *Interner) Clear() {
+	in.Lock()
+	defer in.Unlock()
 
+	in.cache = make(map[uint64][]*KeySet)
+}
+
+// The number of keysets interned
+func (in *Interner) Len() int {
+	in.Lock()
+	defer in.Unlock()
+
+	n := 0
+	for _, possibles := range in.cache {
+		n += len(possibles)
+	}
+
+	return n
+}
+
+// Release a keyset from the default interner
+func ReleaseKeySet(ks *KeySet) {
+	DefaultInterner.Release(ks)
 }
 
 // Build a key set of the keys + a crc64 of the keys (which we can
-// efficiently compare). Also returns an index of string to position
+// efficiently compare), interned in the default interner
 func NewKeySet(keys []string, base float64) *KeySet {
+	return DefaultInterner.NewKeySet(keys, base)
+}
+
+// Build a key set interned in in. Also builds an index of string to
+// position.
+func (in *Interner) NewKeySet(keys []string, base float64) *KeySet {
 	c := crc.New(crc.MakeTable(crc.ISO))
 	index := make(map[string]int)
 
@@ -64,6 +135,127 @@ func NewKeySet(keys []string, base float64) *KeySet {
 		c.Write([]byte(s))
 	}
 
-	return internKeySet(&KeySet{Hash: c.Sum64(), Keys: keys, Positions: index, Base: base})
+	return in.Intern(&KeySet{Hash: c.Sum64(), Keys: keys, Positions: index, Base: base})
+}
+
+// The most bits a hashed keyset can use (2^30 buckets)
+const MaxHashBits = 30
+
+// Build a keyset that hashes keys into 2^bits buckets rather than
+// storing them. Distinct keys may share a bucket; with signed hashing
+// each key also hashes to a sign, so that colliding keys tend to
+// cancel out rather than inflate each other. Hashed keysets always
+// have a base of 0. bits must be between 1 and MaxHashBits.
+func NewHashedKeySet(bits uint, signed bool) *KeySet {
+	return DefaultInterner.NewHashedKeySet(bits, signed)
+}
+
+// Build a hashed keyset interned in in
+func (in *Interner) NewHashedKeySet(bits uint, signed bool) *KeySet {
+	if bits < 1 || bits > MaxHashBits {
+		panic(fmt.Sprintf("hashed keysets need 1 to %d bits, not %d", MaxHashBits, bits))
+	}
+
+	hash := uint64(bits) << 1
+	if signed {
+		hash |= 1
+	}
+
+	return in.Intern(&KeySet{Hash: hash, Bits: bits, Signed: signed})
+}
+
+func (ks *KeySet) hashed() bool {
+	return ks.Bits > 0
+}
+
+func hashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+
+	return h.Sum64()
+}
+
+// The number of positions (keys or buckets) in the keyset
+func (ks *KeySet) Len() int {
+	if ks.hashed() {
+		return 1 << ks.Bits
+	}
+
+	return len(ks.Keys)
+}
+
+// Find the position of key, returning false if it isn't in the keyset
+// (hashed keysets contain every key)
+func (ks *KeySet) Position(key string) (int, bool) {
+	if ks.hashed() {
+		return int(hashKey(key) & (1<<ks.Bits - 1)), true
+	}
+
+	idx, ok := ks.Positions[key]
+	return idx, ok
+}
+
+// The sign values for key are stored with - always 1 unless the
+// keyset uses signed hashing
+func (ks *KeySet) Sign(key string) float64 {
+	if ks.Signed && hashKey(key)>>63 == 1 {
+		return -1.0
+	}
+
+	return 1.0
+}
+
+// The key at a position (for hashed keysets, the name of the bucket)
+func (ks *KeySet) Key(idx int) string {
+	if ks.hashed() {
+		return "#" + strconv.Itoa(idx)
+	}
+
+	return ks.Keys[idx]
+}
+
+func (ks *KeySet) checkUnhashed() {
+	if ks.hashed() {
+		panic("hashed keysets have no keys to combine")
+	}
+}
+
+// The keys of a followed by those of b that aren't in a, with a's base
+func Union(a, b *KeySet) *KeySet {
+	a.checkUnhashed()
+	b.checkUnhashed()
+
+	keys := append([]string{}, a.Keys...)
+	for _, k := range b.Keys {
+		if _, ok := a.Positions[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	return NewKeySet(keys, a.Base)
 }
 
+// The keys of a (in order) which are or aren't in b
+func filterKeys(a, b *KeySet, in bool) *KeySet {
+	a.checkUnhashed()
+	b.checkUnhashed()
+
+	keys := []string{}
+	for _, k := range a.Keys {
+		if _, ok := b.Positions[k]; ok == in {
+			keys = append(keys, k)
+		}
+	}
+
+	return NewKeySet(keys, a.Base)
+}
+
+// The keys of a that are also in b, with a's base
+func Intersection(a, b *KeySet) *KeySet {
+	return filterKeys(a, b, true)
+}
+
+// The keys of a that aren't in b, with a's base
+func Difference(a, b *KeySet) *KeySet {
+	return filterKeys(a, b, false)
+}
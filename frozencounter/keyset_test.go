@@ -0,0 +1,115 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "testing"
+
+func TestHashedKeySet(t *testing.T) {
+	ks := NewHashedKeySet(16, true)
+	if ks != NewHashedKeySet(16, true) || ks == NewHashedKeySet(16, false) {
+		t.Error("Hashed keysets aren't interned by size and signedness")
+	}
+
+	c := counter.New(0.0)
+	c.Set("a", 2.0)
+	c.Set("b", 3.0)
+
+	frozen := FreezeWithKeySet(c, ks)
+	if frozen.Keys.Len() != 1<<16 || len(ks.Keys) != 0 {
+		t.Errorf("Expected 2^16 buckets and no stored keys")
+	}
+
+	frozen.Incr("c")
+	for key, expected := range map[string]float64{"a": 2.0, "b": 3.0, "c": 1.0, "d": 0.0} {
+		if v := frozen.Get(key); v != expected {
+			t.Errorf("Get(%s) = %f, expected %f", key, v, expected)
+		}
+	}
+
+	if dot := Dot(frozen, frozen); dot != 14.0 {
+		t.Errorf("Dot product = %f, expected 14", dot)
+	}
+}
+
+func TestKeySetInterning(t *testing.T) {
+	ks := NewKeySet([]string{"intern-a", "intern-b"}, 0.0)
+	if ks != NewKeySet([]string{"intern-a", "intern-b"}, 0.0) {
+		t.Error("Equal keysets aren't interned")
+	}
+
+	// Same crc (the keys are hashed concatenated) & length, different keys
+	if ks == NewKeySet([]string{"intern-ai", "ntern-b"}, 0.0) {
+		t.Error("Keysets with different keys were interned together")
+	}
+
+	if ks == NewKeySet([]string{"intern-a", "intern-b"}, 1.0) {
+		t.Error("Keysets with different bases were interned together")
+	}
+
+	ReleaseKeySet(ks)
+	if again := NewKeySet([]string{"intern-a", "intern-b"}, 0.0); again == ks {
+		t.Error("Released keyset is still interned")
+	}
+}
+
+func TestInterner(t *testing.T) {
+	in := NewInterner()
+
+	ks := in.NewKeySet([]string{"scope-a"}, 0.0)
+	if ks != in.NewKeySet([]string{"scope-a"}, 0.0) || ks == NewKeySet([]string{"scope-a"}, 0.0) {
+		t.Error("Interners don't have separate scopes")
+	}
+
+	if in.NewHashedKeySet(8, false) != in.NewHashedKeySet(8, false) || in.Len() != 2 {
+		t.Errorf("Expected 2 interned keysets, found %d", in.Len())
+	}
+
+	in.Release(ks)
+	if in.Len() != 1 {
+		t.Errorf("Expected 1 interned keyset after a release, found %d", in.Len())
+	}
+
+	in.Clear()
+	if in.Len() != 0 {
+		t.Errorf("Expected no interned keysets after clearing, found %d", in.Len())
+	}
+}
+
+func TestConcurrentInterning(t *testing.T) {
+	in := NewInterner()
+	results := make(chan *KeySet)
+
+	for i := 0; i < 8; i++ {
+		go func() {
+			var ks *KeySet
+			for j := 0; j < 100; j++ {
+				ks = in.NewKeySet([]string{"race-a", "race-b"}, 0.0)
+			}
+			results <- ks
+		}()
+	}
+
+	first := <-results
+	for i := 1; i < 8; i++ {
+		if <-results != first {
+			t.Error("Concurrent interning built distinct keysets")
+		}
+	}
+}
+
+func TestHashedKeySetBits(t *testing.T) {
+	for _, bits := range []uint{0, MaxHashBits + 1, 63, 64} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for a %d bit hashed keyset", bits)
+				}
+			}()
+
+			NewHashedKeySet(bits, false)
+		}()
+	}
+
+	if ks := NewHashedKeySet(1, false); ks.Len() != 2 {
+		t.Errorf("Expected 2 buckets, found %d", ks.Len())
+	}
+}
@@ -0,0 +1,92 @@
+package frozencounter
+
+// Matrix operations on counter vectors, treating a CounterVector as a
+// matrix with a row per key in Keys and a column per key in SubKeys.
+// These are BLAS level 2 & 3 operations, apart from those involving
+// sparse counters.
+
+func (cv *CounterVector) rows() int {
+	return cv.Keys.Len()
+}
+
+// A view of the row at pos
+func (cv *CounterVector) row(pos int) *Counter {
+	return &Counter{Keys: cv.SubKeys, values: cv.values[pos*cv.size : (pos+1)*cv.size]}
+}
+
+// The product of cv and c (keyed by SubKeys) - the dot product of every
+// row with c, keyed by Keys. E.g. the score of every label given a
+// weight per (label, feature) and feature counts.
+func (cv *CounterVector) MultiplyVector(c *Counter) *Counter {
+	if c.Keys != cv.SubKeys {
+		panic("incompatible keysets")
+	}
+
+	result := &Counter{cv.Keys, make(vector, cv.rows())}
+	gemv(false, cv.rows(), cv.size, 1.0, cv.values, c.values, 0.0, result.values)
+
+	return result
+}
+
+// As MultiplyVector, for a sparse counter
+func (cv *CounterVector) MultiplySparse(s *Sparse) *Counter {
+	if s.Keys != cv.SubKeys {
+		panic("incompatible keysets")
+	}
+
+	result := &Counter{cv.Keys, make(vector, cv.rows())}
+	for pos := range result.values {
+		result.values[pos] = s.Dot(cv.row(pos))
+	}
+
+	return result
+}
+
+// The product of c (keyed by Keys) and cv - the sum of every row scaled
+// by c's value for it, keyed by SubKeys
+func (cv *CounterVector) TransposeMultiplyVector(c *Counter) *Counter {
+	if c.Keys != cv.Keys {
+		panic("incompatible keysets")
+	}
+
+	result := &Counter{cv.SubKeys, make(vector, cv.size)}
+	gemv(true, cv.rows(), cv.size, 1.0, cv.values, c.values, 0.0, result.values)
+
+	return result
+}
+
+// The product of cv and the transpose of o, which must share SubKeys -
+// the dot product of every row of cv with every row of o, keyed by
+// cv.Keys and then o.Keys. E.g. the score of every label for a batch of
+// documents.
+func (cv *CounterVector) MultiplyTransposed(o *CounterVector) *CounterVector {
+	if cv.SubKeys != o.SubKeys {
+		panic("incompatible keysets")
+	}
+
+	result := &CounterVector{Keys: cv.Keys, SubKeys: o.Keys, size: o.rows(), values: make(vector, cv.rows()*o.rows())}
+	gemmTransposed(cv.rows(), o.rows(), cv.size, 1.0, cv.values, o.values, 0.0, result.values)
+
+	return result
+}
+
+// Add scale times the outer product of x (keyed by Keys) and y (keyed
+// by SubKeys) to cv
+func (cv *CounterVector) AddOuter(scale float64, x, y *Counter) {
+	if x.Keys != cv.Keys || y.Keys != cv.SubKeys {
+		panic("incompatible keysets")
+	}
+
+	ger(cv.rows(), cv.size, scale, x.values, y.values, cv.values)
+}
+
+// As AddOuter, for a sparse y
+func (cv *CounterVector) AddOuterSparse(scale float64, x *Counter, y *Sparse) {
+	if x.Keys != cv.Keys || y.Keys != cv.SubKeys {
+		panic("incompatible keysets")
+	}
+
+	for pos, v := range x.values {
+		cv.row(pos).AddScaledSparse(scale*v, y)
+	}
+}
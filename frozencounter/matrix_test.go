@@ -0,0 +1,88 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "testing"
+
+// A 2 x 3 matrix over labels (matrix-x, matrix-y) and features
+// (matrix-f1, matrix-f2, matrix-f3)
+func testMatrix() *CounterVector {
+	features := NewKeySet([]string{"matrix-f1", "matrix-f2", "matrix-f3"}, 0.0)
+	rows := map[string]*Counter{}
+
+	for i, label := range []string{"matrix-x", "matrix-y"} {
+		rows[label] = New(features)
+		for j, f := range features.Keys {
+			rows[label].Set(f, float64(3*i+j+1))
+		}
+	}
+
+	cv, err := NewCounterVector(rows)
+	if err != nil {
+		panic(err)
+	}
+
+	return cv
+}
+
+func TestMatrix(t *testing.T) {
+	cv := testMatrix()
+
+	c := New(cv.SubKeys)
+	c.Set("matrix-f1", 1.0)
+	c.Set("matrix-f3", 2.0)
+
+	// [1 2 3; 4 5 6] * [1 0 2]
+	scores := cv.MultiplyVector(c)
+	if scores.Keys != cv.Keys || scores.Get("matrix-x") != 7 || scores.Get("matrix-y") != 16 {
+		t.Errorf("Bad matrix-vector product %s", scores)
+	}
+
+	sparse := cv.MultiplySparse(c.Sparse())
+	if sparse.Get("matrix-x") != 7 || sparse.Get("matrix-y") != 16 {
+		t.Errorf("Bad sparse matrix-vector product %s", sparse)
+	}
+
+	// [1 -1] * [1 2 3; 4 5 6]
+	x := New(cv.Keys)
+	x.Set("matrix-x", 1.0)
+	x.Set("matrix-y", -1.0)
+	if sum := cv.TransposeMultiplyVector(x); sum.Get("matrix-f1") != -3 || sum.Get("matrix-f3") != -3 {
+		t.Errorf("Bad transposed product %s", sum)
+	}
+
+	// Scoring a batch of documents at once
+	docs := NewCounterVectorWithKeySets(NewKeySet([]string{"matrix-d1", "matrix-d2"}, 0.0), cv.SubKeys)
+	docs.Get("matrix-d1").Set("matrix-f1", 1.0)
+	docs.Get("matrix-d1").Set("matrix-f3", 2.0)
+	docs.Get("matrix-d2").Set("matrix-f2", 1.0)
+
+	batch := cv.MultiplyTransposed(docs)
+	if batch.Keys != cv.Keys || batch.SubKeys != docs.Keys {
+		t.Fatalf("Batch scores have the wrong keysets")
+	}
+	if d1 := batch.Get("matrix-y").Get("matrix-d1"); d1 != 16 || batch.Get("matrix-x").Get("matrix-d2") != 2 {
+		t.Errorf("Bad matrix-matrix product %s", batch)
+	}
+
+	cv.AddOuter(2.0, x, c)
+	if cv.Get("matrix-x").Get("matrix-f1") != 3 || cv.Get("matrix-y").Get("matrix-f3") != 2 || cv.Get("matrix-y").Get("matrix-f2") != 5 {
+		t.Errorf("Bad outer product %s", cv)
+	}
+
+	cv.AddOuterSparse(-2.0, x, c.Sparse())
+	if cv.Get("matrix-x").Get("matrix-f1") != 1 || cv.Get("matrix-y").Get("matrix-f3") != 6 {
+		t.Errorf("Bad sparse outer product %s", cv)
+	}
+}
+
+func TestMatrixIncompatible(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for incompatible keysets")
+		}
+	}()
+
+	other := counter.New(0.0)
+	other.Set("matrix-other", 1.0)
+	testMatrix().MultiplyVector(Freeze(other))
+}
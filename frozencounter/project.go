@@ -0,0 +1,60 @@
+package frozencounter
+
+// Remap c onto ks, e.g. to combine counters frozen over different
+// keysets. Keys of ks missing from c get ks's base; keys of c missing
+// from ks are dropped. Projecting onto a hashed keyset buckets the keys
+// as FreezeWithKeySet does, but hashed counters can only be projected
+// onto their own keyset.
+func Project(c *Counter, ks *KeySet) *Counter {
+	if c.Keys == ks {
+		return c.Copy()
+	}
+
+	c.Keys.checkUnhashed()
+
+	if ks.hashed() {
+		result := New(ks)
+		for idx, v := range c.values {
+			k := c.Keys.Key(idx)
+			pos, _ := ks.Position(k)
+			result.values[pos] += ks.Sign(k) * v
+		}
+
+		return result
+	}
+
+	result := New(ks)
+	for pos, k := range ks.Keys {
+		if idx, ok := c.Keys.Positions[k]; ok {
+			result.values[pos] = c.values[idx]
+		}
+	}
+
+	return result
+}
+
+// Remap cv onto new row and column keysets, as Project does for each
+// row. Rows missing from cv are filled with subKeys' base. Pass
+// cv.Keys or cv.SubKeys to keep the rows or columns as they are.
+func ProjectVector(cv *CounterVector, keys, subKeys *KeySet) *CounterVector {
+	if keys != cv.Keys {
+		cv.Keys.checkUnhashed()
+		keys.checkUnhashed()
+	}
+
+	result := NewCounterVectorWithKeySets(keys, subKeys)
+	result.Reset(subKeys.Base)
+
+	for pos := 0; pos < result.rows(); pos++ {
+		idx, ok := pos, true
+		if keys != cv.Keys {
+			idx, ok = cv.Keys.Positions[keys.Keys[pos]]
+		}
+
+		if ok {
+			copy(result.row(pos).values, Project(cv.row(idx), subKeys).values)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,87 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "testing"
+
+func sameKeys(ks *KeySet, keys ...string) bool {
+	if len(ks.Keys) != len(keys) {
+		return false
+	}
+
+	for idx, k := range keys {
+		if ks.Keys[idx] != k {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestKeySetAlgebra(t *testing.T) {
+	a := NewKeySet([]string{"alg-a", "alg-b", "alg-c"}, 0.0)
+	b := NewKeySet([]string{"alg-c", "alg-d", "alg-a"}, 1.0)
+
+	if u := Union(a, b); !sameKeys(u, "alg-a", "alg-b", "alg-c", "alg-d") || u.Base != 0 {
+		t.Errorf("Bad union %v", u.Keys)
+	}
+
+	if i := Intersection(a, b); !sameKeys(i, "alg-a", "alg-c") {
+		t.Errorf("Bad intersection %v", i.Keys)
+	}
+
+	if d := Difference(a, b); !sameKeys(d, "alg-b") {
+		t.Errorf("Bad difference %v", d.Keys)
+	}
+
+	if Union(a, a) != a || Intersection(b, b) != b {
+		t.Errorf("Combining a keyset with itself didn't return it")
+	}
+}
+
+func TestProject(t *testing.T) {
+	raw := counter.New(0.0)
+	raw.Set("proj-a", 1.0)
+	raw.Set("proj-b", 2.0)
+	c := Freeze(raw)
+
+	target := NewKeySet([]string{"proj-b", "proj-c"}, -1.0)
+	p := Project(c, target)
+	if p.Keys != target || p.Get("proj-b") != 2 || p.Get("proj-c") != -1 || p.Get("proj-a") != -1 {
+		t.Errorf("Bad projection %s", p)
+	}
+
+	// Merging counters over different keysets
+	other := Freeze(counter.New(0.0))
+	union := Union(c.Keys, target)
+	sum := Add(Project(c, union), Project(other, union))
+	if sum.Get("proj-a") != 1 || sum.Get("proj-c") != 0 {
+		t.Errorf("Bad merged counter %s", sum)
+	}
+
+	hashed := Project(c, NewHashedKeySet(8, false))
+	if hashed.Get("proj-a") != 1 || hashed.Get("proj-b") != 2 {
+		t.Errorf("Bad projection onto a hashed keyset")
+	}
+}
+
+func TestProjectVector(t *testing.T) {
+	cv := testMatrix()
+
+	keys := NewKeySet([]string{"matrix-y", "matrix-z"}, 0.0)
+	subKeys := NewKeySet([]string{"matrix-f3", "matrix-f4"}, 0.0)
+	p := ProjectVector(cv, keys, subKeys)
+
+	if p.Keys != keys || p.SubKeys != subKeys {
+		t.Fatalf("Projection has the wrong keysets")
+	}
+
+	if p.Get("matrix-y").Get("matrix-f3") != 6 || p.Get("matrix-y").Get("matrix-f4") != 0 || p.Get("matrix-z").Get("matrix-f3") != 0 {
+		t.Errorf("Bad vector projection %s", p)
+	}
+
+	// Only remapping the columns
+	cols := ProjectVector(cv, cv.Keys, subKeys)
+	if cols.Keys != cv.Keys || cols.Get("matrix-x").Get("matrix-f3") != 3 {
+		t.Errorf("Bad column projection %s", cols)
+	}
+}
@@ -0,0 +1,84 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "math"
+import "rand"
+
+// Sampling from frozen counters of (possibly unnormalized) weights or
+// log weights. Keys are visited in keyset order, so a seeded source
+// reproduces the same samples.
+
+// exp(v - max(v)), for turning log weights into weights without
+// overflow
+func shiftedExp(v vector) vector {
+	max := math.Inf(-1)
+	for _, x := range v {
+		if x > max {
+			max = x
+		}
+	}
+
+	result := make(vector, len(v))
+	for i, x := range v {
+		result[i] = math.Exp(x - max)
+	}
+
+	return result
+}
+
+func (c *Counter) pick(weights vector, r *rand.Rand) string {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+
+	if total <= 0 || math.IsNaN(total) {
+		panic("can't sample from a counter with no weight")
+	}
+
+	target := r.Float64() * total
+	last := 0
+	for idx, w := range weights {
+		target -= w
+		if target < 0 {
+			return c.Keys.Key(idx)
+		}
+
+		if w > 0 {
+			last = idx
+		}
+	}
+
+	// Rounding error
+	return c.Keys.Key(last)
+}
+
+// Draw a key with probability proportional to its value
+func (c *Counter) Sample(r *rand.Rand) string {
+	return c.pick(c.values, r)
+}
+
+// Draw a key from a counter of log weights, e.g. after LogNormalize
+func (c *Counter) LogSample(r *rand.Rand) string {
+	return c.pick(shiftedExp(c.values), r)
+}
+
+func (c *Counter) keys() []string {
+	keys := make([]string, len(c.values))
+	for idx := range keys {
+		keys[idx] = c.Keys.Key(idx)
+	}
+
+	return keys
+}
+
+// Build an alias table for drawing many samples from a counter of
+// weights
+func NewAlias(c *Counter) *counter.Alias {
+	return counter.NewAliasTable(c.keys(), c.values)
+}
+
+// Build an alias table for a counter of log weights
+func NewLogAlias(c *Counter) *counter.Alias {
+	return counter.NewAliasTable(c.keys(), shiftedExp(c.values))
+}
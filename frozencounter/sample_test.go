@@ -0,0 +1,38 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "math"
+import "rand"
+import "testing"
+
+func TestSample(t *testing.T) {
+	weights := counter.New(0.0)
+	weights.Set("a", 1.0)
+	weights.Set("b", 3.0)
+
+	frozen := Freeze(weights)
+	logFrozen := frozen.Copy()
+	logFrozen.Log()
+
+	r := rand.New(rand.NewSource(1))
+	alias := NewLogAlias(logFrozen)
+
+	draws := []func() string{
+		func() string { return frozen.Sample(r) },
+		func() string { return logFrozen.LogSample(r) },
+		func() string { return alias.Sample(r) },
+	}
+
+	for i, draw := range draws {
+		n, b := 10000, 0.0
+		for j := 0; j < n; j++ {
+			if draw() == "b" {
+				b++
+			}
+		}
+
+		if math.Abs(b/float64(n)-0.75) > 0.02 {
+			t.Errorf("Sampler %d drew b %.3f of the time, expected 0.75", i, b/float64(n))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "fmt"
+import "sort"
+
+// A frozen counter storing only the values that differ from its
+// keyset's base, as positions into the keyset (in increasing order)
+// and their values. Suited to vectors touching few of a large
+// keyset's keys, e.g. the features of a single document.
+type Sparse struct {
+	Keys      *KeySet
+	positions []int
+	values    []float64
+}
+
+type byPosition Sparse
+
+func (s *byPosition) Len() int           { return len(s.positions) }
+func (s *byPosition) Less(i, j int) bool { return s.positions[i] < s.positions[j] }
+func (s *byPosition) Swap(i, j int) {
+	s.positions[i], s.positions[j] = s.positions[j], s.positions[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+// Freeze a counter into a sparse counter over ks, which must have the
+// same base as c. Keys missing from ks are dropped, and keys sharing a
+// bucket of a hashed keyset are summed.
+func FreezeSparse(c *counter.Counter, ks *KeySet) *Sparse {
+	if c.Base != ks.Base {
+		panic("counter and keyset have different bases")
+	}
+
+	values := make(map[int]float64)
+	for _, k := range c.Keys() {
+		if idx, ok := ks.Position(k); ok {
+			values[idx] += ks.Sign(k) * c.Get(k)
+		}
+	}
+
+	s := &Sparse{Keys: ks, positions: make([]int, 0, len(values)), values: make([]float64, 0, len(values))}
+	for idx, v := range values {
+		if v != ks.Base {
+			s.positions = append(s.positions, idx)
+			s.values = append(s.values, v)
+		}
+	}
+
+	sort.Sort((*byPosition)(s))
+	return s
+}
+
+// Convert a dense counter into a sparse one
+func (c *Counter) Sparse() *Sparse {
+	s := &Sparse{Keys: c.Keys}
+	for idx, v := range c.values {
+		if v != c.Keys.Base {
+			s.positions = append(s.positions, idx)
+			s.values = append(s.values, v)
+		}
+	}
+
+	return s
+}
+
+// Convert a sparse counter into a dense one
+func (s *Sparse) Dense() *Counter {
+	c := New(s.Keys)
+	for i, idx := range s.positions {
+		c.values[idx] = s.values[i]
+	}
+
+	return c
+}
+
+// The number of values stored
+func (s *Sparse) Len() int {
+	return len(s.positions)
+}
+
+func (s *Sparse) Get(f string) float64 {
+	idx, ok := s.Keys.Position(f)
+	if !ok {
+		return s.Keys.Base
+	}
+
+	if i := sort.SearchInts(s.positions, idx); i < len(s.positions) && s.positions[i] == idx {
+		return s.Keys.Sign(f) * s.values[i]
+	}
+
+	return s.Keys.Base
+}
+
+func (s *Sparse) String() string {
+	result := "SparseCounter: {"
+
+	for i, idx := range s.positions {
+		result += fmt.Sprintf("'%s': %f, ", s.Keys.Key(idx), s.values[i])
+	}
+
+	return result + "}"
+}
+
+func (s *Sparse) check(c *Counter) {
+	if s.Keys != c.Keys {
+		panic("incompatible keysets")
+	}
+}
+
+// The sum over the values of c at positions s doesn't store
+func (s *Sparse) unstoredSum(c *Counter) float64 {
+	sum, next := 0.0, 0
+	for idx, v := range c.values {
+		if next < len(s.positions) && s.positions[next] == idx {
+			next++
+			continue
+		}
+		sum += v
+	}
+
+	return sum
+}
+
+// Compute the dot product of s & c
+func (s *Sparse) Dot(c *Counter) float64 {
+	s.check(c)
+
+	dot := 0.0
+	for i, idx := range s.positions {
+		dot += s.values[i] * c.values[idx]
+	}
+
+	if s.Keys.Base != 0 {
+		dot += s.Keys.Base * s.unstoredSum(c)
+	}
+
+	return dot
+}
+
+// Add scale * s to c
+func (c *Counter) AddScaledSparse(scale float64, s *Sparse) {
+	s.check(c)
+
+	if base := s.Keys.Base; base != 0 {
+		next := 0
+		for idx := range c.values {
+			if next < len(s.positions) && s.positions[next] == idx {
+				next++
+				continue
+			}
+			c.values[idx] += scale * base
+		}
+	}
+
+	for i, idx := range s.positions {
+		c.values[idx] += scale * s.values[i]
+	}
+}
@@ -0,0 +1,84 @@
+package frozencounter
+
+import counter "gnlp/counter"
+import "testing"
+
+func TestSparse(t *testing.T) {
+	ks := NewKeySet([]string{"sparse-a", "sparse-b", "sparse-c", "sparse-d"}, 0.0)
+
+	doc := counter.New(0.0)
+	doc.Set("sparse-c", 2.0)
+	doc.Set("sparse-a", 1.0)
+	doc.Set("unknown", 5.0)
+
+	s := FreezeSparse(doc, ks)
+	if s.Len() != 2 || s.Get("sparse-a") != 1 || s.Get("sparse-c") != 2 || s.Get("sparse-b") != 0 || s.Get("unknown") != 0 {
+		t.Fatalf("Bad sparse counter %s", s)
+	}
+
+	dense := s.Dense()
+	if dense.Get("sparse-c") != 2 || dense.Sparse().Len() != 2 || dense.Sparse().Get("sparse-a") != 1 {
+		t.Errorf("Bad dense conversion %s", dense)
+	}
+
+	weights := New(ks)
+	weights.Set("sparse-a", 0.5)
+	weights.Set("sparse-b", 3.0)
+	weights.Set("sparse-c", -1.0)
+
+	if dot := s.Dot(weights); dot != Dot(dense, weights) || dot != -1.5 {
+		t.Errorf("Dot = %f, expected -1.5", dot)
+	}
+
+	weights.AddScaledSparse(2.0, s)
+	if weights.Get("sparse-a") != 2.5 || weights.Get("sparse-b") != 3 || weights.Get("sparse-c") != 3 {
+		t.Errorf("Bad scaled add %s", weights)
+	}
+}
+
+func TestSparseBase(t *testing.T) {
+	ks := NewKeySet([]string{"based-a", "based-b", "based-c"}, 1.0)
+
+	c := New(ks)
+	c.Set("based-b", 4.0)
+
+	s := c.Sparse()
+	if s.Len() != 1 || s.Get("based-a") != 1 {
+		t.Fatalf("Bad sparse counter %s", s)
+	}
+
+	ones := New(ks)
+	if dot := s.Dot(ones); dot != 6 {
+		t.Errorf("Dot = %f, expected 6", dot)
+	}
+
+	ones.AddScaledSparse(1.0, s)
+	if ones.Get("based-a") != 2 || ones.Get("based-b") != 5 {
+		t.Errorf("Bad scaled add %s", ones)
+	}
+}
+
+func TestFreezeSparseMatchesDense(t *testing.T) {
+	for _, base := range []float64{0.0, 1.0} {
+		ks := NewKeySet([]string{"match-a", "match-b", "match-c"}, base)
+
+		c := counter.New(base)
+		c.Set("match-a", 3.0)
+		c.Set("match-c", -1.0)
+
+		sparse, dense := FreezeSparse(c, ks).Dense(), FreezeWithKeySet(c, ks)
+		for _, k := range ks.Keys {
+			if sparse.Get(k) != dense.Get(k) {
+				t.Errorf("Base %f: sparse %s differs from dense %s", base, sparse, dense)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected a panic for mismatched bases")
+		}
+	}()
+
+	FreezeSparse(counter.New(1.0), NewKeySet([]string{"match-a"}, 0.0))
+}
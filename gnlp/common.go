@@ -1,14 +1,24 @@
 package gnlp
 
-type Counter interface {
+// Reading and incrementing counts, which approximate counters (see
+// the sketch package) support too
+type Tally interface {
 	Get(string) float64
-	Set(string, float64)
 	Incr(string)
+}
+
+type Counter interface {
+	Tally
+	Set(string, float64)
 
 	Log()
 	Exp()
 	Normalize()
 	LogNormalize()
 
+	// Log-space arithmetic, for counters of log probabilities
+	LogSumExp() float64
+	LogRenormalize()
+
 	Apply(op func(*string, float64) float64)
 }
@@ -0,0 +1,224 @@
+package ngram
+
+import counter "gnlp/counter"
+import "gnlp/features"
+import "bufio"
+import "fmt"
+import "io"
+import "math"
+import "os"
+import "sort"
+import "strconv"
+import "strings"
+
+// ARPA files store log10 probabilities, and use -99 for log(0)
+const arpaZero = -99.0
+
+func toARPA(x float64) float64 {
+	if math.IsInf(x, -1) {
+		return arpaZero
+	}
+
+	return x / math.Ln10
+}
+
+func fromARPA(x float64) float64 {
+	if x <= arpaZero {
+		return math.Inf(-1)
+	}
+
+	return x * math.Ln10
+}
+
+type arpaEntry struct {
+	words      []string
+	prob       float64
+	backoff    float64
+	hasBackoff bool
+}
+
+// Collect the model's n-grams, bucketed by order and sorted
+func (m *Model) arpaEntries() [][]*arpaEntry {
+	byKey := make([]map[string]*arpaEntry, m.Order)
+	for idx, _ := range byKey {
+		byKey[idx] = make(map[string]*arpaEntry)
+	}
+
+	entry := func(words []string) *arpaEntry {
+		key := features.NGramKey(words)
+		e, ok := byKey[len(words)-1][key]
+		if !ok {
+			e = &arpaEntry{words: words, prob: math.Inf(-1)}
+			byKey[len(words)-1][key] = e
+		}
+
+		return e
+	}
+
+	for h, dist := range m.probs {
+		history := m.histories[h]
+
+		for _, w := range dist.Keys() {
+			words := make([]string, len(history), len(history)+1)
+			copy(words, history)
+
+			entry(append(words, w)).prob = dist.Get(w)
+		}
+	}
+
+	for h, weight := range m.backoffs {
+		if history := m.histories[h]; len(history) < m.Order {
+			e := entry(history)
+			e.backoff = weight
+			e.hasBackoff = true
+		}
+	}
+
+	result := make([][]*arpaEntry, m.Order)
+	for idx, entries := range byKey {
+		keys := make([]string, 0, len(entries))
+		for key, _ := range entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			result[idx] = append(result[idx], entries[key])
+		}
+	}
+
+	return result
+}
+
+// Write the model's probabilities and backoff weights in the ARPA
+// text format used by SRILM, KenLM et al.
+func (m *Model) WriteARPA(w io.Writer) os.Error {
+	out := bufio.NewWriter(w)
+	entries := m.arpaEntries()
+
+	fmt.Fprintf(out, "\\data\\\n")
+	for idx, ngrams := range entries {
+		fmt.Fprintf(out, "ngram %d=%d\n", idx+1, len(ngrams))
+	}
+
+	for idx, ngrams := range entries {
+		fmt.Fprintf(out, "\n\\%d-grams:\n", idx+1)
+
+		for _, e := range ngrams {
+			fmt.Fprintf(out, "%.7g\t%s", toARPA(e.prob), strings.Join(e.words, " "))
+			if e.hasBackoff {
+				fmt.Fprintf(out, "\t%.7g", toARPA(e.backoff))
+			}
+			fmt.Fprintf(out, "\n")
+		}
+	}
+
+	fmt.Fprintf(out, "\n\\end\\\n")
+
+	return out.Flush()
+}
+
+// Load a model from an ARPA file. The model can be queried with
+// LogProb et al, but has no counts to re-estimate from.
+func ReadARPA(r io.Reader) (*Model, os.Error) {
+	in := bufio.NewReader(r)
+	expected := []int{}
+	counts := []int{}
+	section := -1
+	var m *Model
+
+	for lineNo := 1; ; lineNo++ {
+		line, err := in.ReadString('\n')
+		if err != nil && err != os.EOF {
+			return nil, err
+		}
+		if err == os.EOF && line == "" {
+			return nil, fmt.Errorf("arpa: missing \\end\\ marker")
+		}
+
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			continue
+		case line == "\\data\\":
+			section = 0
+			continue
+		case line == "\\end\\":
+			for idx, n := range expected {
+				if counts[idx] != n {
+					return nil, fmt.Errorf("arpa: expected %d %d-grams, found %d", n, idx+1, counts[idx])
+				}
+			}
+
+			if m == nil {
+				return nil, fmt.Errorf("arpa: no n-grams found")
+			}
+			return m, nil
+		case section < 0:
+			// Anything before \data\ is a comment
+			continue
+		case strings.HasPrefix(line, "ngram "):
+			var order, n int
+			if _, err := fmt.Sscanf(line, "ngram %d=%d", &order, &n); err != nil || order != len(expected)+1 {
+				return nil, fmt.Errorf("arpa: line %d: bad header %q", lineNo, line)
+			}
+
+			expected = append(expected, n)
+			counts = append(counts, 0)
+			continue
+		case strings.HasPrefix(line, "\\"):
+			if _, err := fmt.Sscanf(line, "\\%d-grams:", &section); err != nil || section < 1 || section > len(expected) {
+				return nil, fmt.Errorf("arpa: line %d: bad section %q", lineNo, line)
+			}
+
+			if m == nil {
+				m = New(len(expected))
+			}
+			continue
+		}
+
+		if section < 1 {
+			return nil, fmt.Errorf("arpa: line %d: n-gram outside of a section", lineNo)
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != section+1 && len(fields) != section+2 {
+			return nil, fmt.Errorf("arpa: line %d: expected a %d-gram, found %q", lineNo, section, line)
+		}
+
+		prob, err := strconv.Atof64(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("arpa: line %d: bad probability %q", lineNo, fields[0])
+		}
+
+		words := fields[1 : section+1]
+		history := words[:section-1]
+		h := features.NGramKey(history)
+		m.histories[h] = history
+
+		if prob := fromARPA(prob); !math.IsInf(prob, -1) {
+			dist, ok := m.probs[h]
+			if !ok {
+				dist = counter.New(math.Inf(-1))
+				m.probs[h] = dist
+			}
+
+			dist.Set(words[section-1], prob)
+		}
+
+		if len(fields) == section+2 {
+			backoff, err := strconv.Atof64(fields[section+1])
+			if err != nil {
+				return nil, fmt.Errorf("arpa: line %d: bad backoff weight %q", lineNo, fields[section+1])
+			}
+
+			key := features.NGramKey(words)
+			m.histories[key] = words
+			m.backoffs[key] = fromARPA(backoff)
+		}
+
+		counts[section-1]++
+	}
+
+	panic("unreachable")
+}
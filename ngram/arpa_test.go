@@ -0,0 +1,82 @@
+package ngram
+
+import "bytes"
+import "math"
+import "strings"
+import "testing"
+
+const arpaModel = `
+\data\
+ngram 1=4
+ngram 2=2
+
+\1-grams:
+-99	<s>	-0.30103
+-0.30103	a	-0.5
+-0.60206	b
+-0.60206	</s>
+
+\2-grams:
+-0.1	<s> a
+-0.2	a b
+
+\end\
+`
+
+func TestReadARPA(t *testing.T) {
+	m, err := ReadARPA(strings.NewReader(arpaModel))
+	if err != nil {
+		t.Fatalf("Failed to read model: %v", err)
+	}
+
+	expected := []struct {
+		history []string
+		word    string
+		log10   float64
+	}{
+		{[]string{Start}, "a", -0.1},
+		{[]string{"a"}, "b", -0.2},
+		{[]string{"a"}, End, -0.5 - 0.60206},
+		{[]string{Start}, "b", -0.30103 - 0.60206},
+		{[]string{"b"}, "a", -0.30103},
+	}
+
+	for _, e := range expected {
+		if p := m.LogProb(e.history, e.word) / math.Ln10; math.Abs(p-e.log10) > 1e-9 {
+			t.Errorf("log10 P(%s | %v) = %f, expected %f", e.word, e.history, p, e.log10)
+		}
+	}
+}
+
+func TestReadARPAErrors(t *testing.T) {
+	broken := strings.Replace(arpaModel, "ngram 2=2", "ngram 2=3", 1)
+	if _, err := ReadARPA(strings.NewReader(broken)); err == nil {
+		t.Error("Mismatched n-gram counts weren't reported")
+	}
+
+	truncated := strings.Replace(arpaModel, "\\end\\", "", 1)
+	if _, err := ReadARPA(strings.NewReader(truncated)); err == nil {
+		t.Error("Missing \\end\\ marker wasn't reported")
+	}
+}
+
+func TestARPARoundTrip(t *testing.T) {
+	m := train(3, WittenBell)
+
+	buf := new(bytes.Buffer)
+	if err := m.WriteARPA(buf); err != nil {
+		t.Fatalf("Failed to write model: %v", err)
+	}
+
+	loaded, err := ReadARPA(buf)
+	if err != nil {
+		t.Fatalf("Failed to read model back: %v", err)
+	}
+
+	for _, sentence := range append(corpus, "a cat sat", "the unseen dog") {
+		words := strings.Fields(sentence)
+		if a, b := m.SentenceLogProb(words), loaded.SentenceLogProb(words); math.Abs(a-b) > 1e-4 {
+			t.Errorf("Round-tripped model scores %q as %f, expected %f", sentence, b, a)
+		}
+	}
+}
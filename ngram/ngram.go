@@ -0,0 +1,247 @@
+package ngram
+
+import counter "gnlp/counter"
+import "gnlp/features"
+import "math"
+import "rand"
+
+// Sentence boundary and unknown word markers
+const (
+	Start   = "<s>"
+	End     = "</s>"
+	Unknown = "<unk>"
+)
+
+// A backoff n-gram language model. Histories are keyed by
+// features.NGramKey (the empty string for unigrams).
+type Model struct {
+	Order int
+
+	// history -> word -> count
+	counts map[string]*counter.Counter
+	// history -> the words making it up
+	histories map[string][]string
+	// history -> word -> log P(word | history), for observed words
+	probs map[string]*counter.Counter
+	// history -> log backoff weight
+	backoffs map[string]float64
+}
+
+func New(order int) *Model {
+	if order < 1 {
+		panic("n-gram order must be at least 1")
+	}
+
+	return &Model{Order: order, counts: make(map[string]*counter.Counter), histories: make(map[string][]string), probs: make(map[string]*counter.Counter), backoffs: make(map[string]float64)}
+}
+
+// Wrap a sentence in boundary markers
+func pad(sentence []string) []string {
+	padded := make([]string, 0, len(sentence)+2)
+	padded = append(padded, Start)
+	padded = append(padded, sentence...)
+
+	return append(padded, End)
+}
+
+// Count the n-grams (of every order up to m.Order) in a single
+// sentence. The sentence shouldn't include boundary markers.
+func (m *Model) Observe(sentence []string) {
+	padded := pad(sentence)
+
+	for i := 1; i < len(padded); i++ {
+		for n := 0; n < m.Order && n <= i; n++ {
+			h := features.NGramKey(padded[i-n : i])
+
+			c, ok := m.counts[h]
+			if !ok {
+				c = counter.New(0.0)
+				m.counts[h] = c
+				m.histories[h] = padded[i-n : i]
+			}
+
+			c.Incr(padded[i])
+		}
+	}
+}
+
+// Count every sentence read from sentences, then estimate the model
+func (m *Model) Train(sentences <-chan []string, s Smoother) {
+	for sentence := range sentences {
+		m.Observe(sentence)
+	}
+
+	m.Estimate(s)
+}
+
+// The raw count of word following history
+func (m *Model) Count(history []string, word string) float64 {
+	c, ok := m.counts[features.NGramKey(history)]
+	if !ok {
+		return 0.0
+	}
+
+	return c.Get(word)
+}
+
+// Turn the observed counts into (log) probabilities and backoff
+// weights, lowest order first.
+func (m *Model) Estimate(s Smoother) {
+	m.probs = make(map[string]*counter.Counter)
+	m.backoffs = make(map[string]float64)
+
+	unigrams, ok := m.counts[""]
+	if !ok {
+		return
+	}
+
+	dist := s(unigrams)
+	if leftover := 1.0 - dist.Sum(); leftover > 0 {
+		dist.Set(Unknown, dist.Get(Unknown)+leftover)
+	}
+	dist.Log()
+	m.probs[""] = dist
+
+	// Histories, bucketed by length
+	byOrder := make([][]string, m.Order)
+	for h, words := range m.histories {
+		// Histories loaded from an ARPA file have no counts
+		if _, ok := m.counts[h]; ok {
+			byOrder[len(words)] = append(byOrder[len(words)], h)
+		}
+	}
+
+	for n := 1; n < m.Order; n++ {
+		for _, h := range byOrder[n] {
+			words := m.histories[h]
+			dist := s(m.counts[h])
+
+			seen, lowerSeen := 0.0, 0.0
+			for _, w := range dist.Keys() {
+				seen += dist.Get(w)
+				lowerSeen += math.Exp(m.LogProb(words[1:], w))
+			}
+
+			if seen < 1.0 && lowerSeen < 1.0 {
+				m.backoffs[h] = math.Log(1.0-seen) - math.Log(1.0-lowerSeen)
+			} else {
+				m.backoffs[h] = math.Inf(-1)
+			}
+
+			dist.Log()
+			m.probs[h] = dist
+		}
+	}
+}
+
+// log P(word | history) (natural log), backing off to shorter
+// histories when word wasn't observed after history. Only the last
+// Order-1 words of history are used.
+func (m *Model) LogProb(history []string, word string) float64 {
+	if len(history) > m.Order-1 {
+		history = history[len(history)-(m.Order-1):]
+	}
+
+	backoff := 0.0
+	for ; len(history) > 0; history = history[1:] {
+		h := features.NGramKey(history)
+
+		if dist, ok := m.probs[h]; ok {
+			if p := dist.Get(word); !math.IsInf(p, -1) {
+				return backoff + p
+			}
+		}
+
+		if weight, ok := m.backoffs[h]; ok {
+			backoff += weight
+		}
+	}
+
+	unigrams, ok := m.probs[""]
+	if !ok {
+		return math.Inf(-1)
+	}
+
+	if p := unigrams.Get(word); !math.IsInf(p, -1) {
+		return backoff + p
+	}
+
+	return backoff + unigrams.Get(Unknown)
+}
+
+// The log probability of a sentence, including the end-of-sentence
+// marker. The sentence shouldn't include boundary markers.
+func (m *Model) SentenceLogProb(sentence []string) float64 {
+	padded := pad(sentence)
+	total := 0.0
+
+	for i := 1; i < len(padded); i++ {
+		total += m.LogProb(padded[:i], padded[i])
+	}
+
+	return total
+}
+
+// Perplexity of the model over a test corpus, counting each sentence's
+// end-of-sentence marker as a token. The perplexity of an empty corpus
+// is +Inf, so it never compares as better than a real one.
+func (m *Model) Perplexity(sentences [][]string) float64 {
+	logProb, tokens := 0.0, 0
+
+	for _, sentence := range sentences {
+		logProb += m.SentenceLogProb(sentence)
+		tokens += len(sentence) + 1
+	}
+
+	if tokens == 0 {
+		return math.Inf(1)
+	}
+
+	return math.Exp(-logProb / float64(tokens))
+}
+
+// The distribution over the next word given history (including the
+// unknown word if it has any mass).
+func (m *Model) Distribution(history []string) *counter.Counter {
+	result := counter.New(0.0)
+
+	unigrams, ok := m.probs[""]
+	if !ok {
+		return result
+	}
+
+	for _, w := range unigrams.Keys() {
+		result.Set(w, math.Exp(m.LogProb(history, w)))
+	}
+
+	return result
+}
+
+// Sample a sentence from the model, stopping at the end-of-sentence
+// marker or after maxLength words. The unknown word is never sampled.
+func (m *Model) Generate(r *rand.Rand, maxLength int) []string {
+	sentence := []string{}
+	history := []string{Start}
+
+	if _, ok := m.probs[""]; !ok {
+		return sentence
+	}
+
+	for len(sentence) < maxLength {
+		dist := m.Distribution(history)
+		dist.Set(Unknown, 0.0)
+		if len(dist.Keys()) == 0 {
+			break
+		}
+
+		word := dist.Sample(r)
+		if word == End {
+			break
+		}
+
+		sentence = append(sentence, word)
+		history = append(history, word)
+	}
+
+	return sentence
+}
@@ -0,0 +1,85 @@
+package ngram
+
+import "math"
+import "rand"
+import "strings"
+import "testing"
+
+var corpus = []string{
+	"the cat sat",
+	"the dog sat",
+	"the cat ran",
+	"a dog ran",
+}
+
+func train(order int, s Smoother) *Model {
+	m := New(order)
+	for _, sentence := range corpus {
+		m.Observe(strings.Fields(sentence))
+	}
+	m.Estimate(s)
+
+	return m
+}
+
+func TestDistributionSumsToOne(t *testing.T) {
+	m := train(3, WittenBell)
+
+	for _, history := range [][]string{{}, {Start}, {Start, "the"}, {"the", "cat"}, {"a", "cat"}, {"unseen"}} {
+		if sum := m.Distribution(history).Sum(); math.Abs(sum-1.0) > 1e-9 {
+			t.Errorf("P(. | %v) sums to %f", history, sum)
+		}
+	}
+}
+
+func TestMaximumLikelihood(t *testing.T) {
+	m := train(2, MaximumLikelihood)
+
+	if p := math.Exp(m.LogProb([]string{"the"}, "cat")); math.Abs(p-2.0/3.0) > 1e-9 {
+		t.Errorf("P(cat | the) = %f, expected 2/3", p)
+	}
+
+	if p := m.LogProb([]string{"the"}, "ran"); !math.IsInf(p, -1) {
+		t.Errorf("P(ran | the) = %f, expected 0", math.Exp(p))
+	}
+}
+
+func TestPerplexity(t *testing.T) {
+	m := train(2, AbsoluteDiscount(0.5))
+
+	seen := m.Perplexity([][]string{strings.Fields("the cat sat")})
+	unseen := m.Perplexity([][]string{strings.Fields("a cat sat")})
+
+	if math.IsInf(unseen, 0) || seen >= unseen {
+		t.Errorf("Perplexity of a seen sentence (%f) should be below an unseen one (%f)", seen, unseen)
+	}
+
+	if empty := m.Perplexity([][]string{}); !math.IsInf(empty, 1) {
+		t.Errorf("Perplexity of an empty corpus = %f, expected +Inf", empty)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	m := train(2, MaximumLikelihood)
+
+	sentence := m.Generate(rand.New(rand.NewSource(1)), 10)
+	if len(sentence) != 3 || m.SentenceLogProb(sentence) == math.Inf(-1) {
+		t.Errorf("Generated an impossible sentence: %v", sentence)
+	}
+}
+
+func TestGenerateSkipsUnknown(t *testing.T) {
+	m := train(2, AbsoluteDiscount(0.9))
+	if m.Distribution([]string{Start}).Get(Unknown) == 0 {
+		t.Fatalf("Expected the unknown word to have some mass")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		for _, w := range m.Generate(r, 10) {
+			if w == Unknown {
+				t.Fatalf("Generated the unknown word")
+			}
+		}
+	}
+}
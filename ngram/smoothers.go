@@ -0,0 +1,63 @@
+package ngram
+
+import "gnlp"
+import counter "gnlp/counter"
+
+// A Smoother estimates P(word | history) from the counts of the words
+// observed after a history. It returns the probabilities of the
+// observed words; whatever mass they leave over is handed to the
+// lower-order model through the history's backoff weight.
+type Smoother func(counts *counter.Counter) *counter.Counter
+
+// Relative frequencies - leaves no mass for unseen words
+func MaximumLikelihood(counts *counter.Counter) *counter.Counter {
+	result := counts.Copy()
+	result.Base = 0.0
+	result.Normalize()
+
+	return result
+}
+
+// Subtract a fixed discount from every observed count, reserving the
+// subtracted mass for the lower-order model
+func AbsoluteDiscount(discount float64) Smoother {
+	return func(counts *counter.Counter) *counter.Counter {
+		total := counts.Sum()
+		result := counter.New(0.0)
+
+		for _, k := range counts.Keys() {
+			if v := counts.Get(k) - discount; v > 0 {
+				result.Set(k, v/total)
+			}
+		}
+
+		return result
+	}
+}
+
+// Witten-Bell smoothing: reserve mass for unseen words in proportion
+// to the number of distinct words seen after the history
+func WittenBell(counts *counter.Counter) *counter.Counter {
+	keys := counts.Keys()
+	total := counts.Sum() + float64(len(keys))
+	result := counter.New(0.0)
+
+	for _, k := range keys {
+		result.Set(k, counts.Get(k)/total)
+	}
+
+	return result
+}
+
+// Adapt one of the in-place smoothing functions (e.g. from
+// gnlp/smoothing) into a Smoother. fn is handed a copy of the counts
+// and must leave a normalized distribution behind.
+func Using(fn func(gnlp.Counter)) Smoother {
+	return func(counts *counter.Counter) *counter.Counter {
+		result := counts.Copy()
+		fn(result)
+		result.Base = 0.0
+
+		return result
+	}
+}
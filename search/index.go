@@ -0,0 +1,96 @@
+package search
+
+import counter "gnlp/counter"
+import "gnlp/tfidf"
+import "gob"
+import "io"
+import "os"
+
+// An occurrence of a term in a document
+type Posting struct {
+	Doc   int
+	Count float64
+}
+
+// An in-memory inverted index over bag-of-words documents
+type Index struct {
+	// Document ids and lengths, indexed by document number
+	IDs     []string
+	Lengths []float64
+
+	// term -> the documents containing it, in document order
+	Postings map[string][]Posting
+
+	// Document frequencies, document count and total length
+	Corpus *tfidf.Corpus
+	// term -> count over every document
+	CollectionFrequency *counter.Counter
+}
+
+func New() *Index {
+	return &Index{Postings: make(map[string][]Posting), Corpus: tfidf.New(), CollectionFrequency: counter.New(0.0)}
+}
+
+// Add a document (term -> count) to the index, returning its document
+// number
+func (idx *Index) Add(id string, doc *counter.Counter) int {
+	n := len(idx.IDs)
+
+	for _, term := range doc.Keys() {
+		count := doc.Get(term)
+
+		idx.Postings[term] = append(idx.Postings[term], Posting{Doc: n, Count: count})
+		idx.CollectionFrequency.Set(term, idx.CollectionFrequency.Get(term)+count)
+	}
+
+	idx.Corpus.Add(doc)
+	idx.IDs = append(idx.IDs, id)
+	idx.Lengths = append(idx.Lengths, doc.Sum()-doc.Base)
+
+	return n
+}
+
+// The parts of the index that aren't derived from the postings
+type savedIndex struct {
+	IDs      []string
+	Lengths  []float64
+	Postings map[string][]Posting
+}
+
+// Write the index to w
+func (idx *Index) Save(w io.Writer) os.Error {
+	return gob.NewEncoder(w).Encode(savedIndex{IDs: idx.IDs, Lengths: idx.Lengths, Postings: idx.Postings})
+}
+
+// Read an index written by Save
+func Load(r io.Reader) (*Index, os.Error) {
+	saved := savedIndex{}
+	if err := gob.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, err
+	}
+
+	idx := New()
+	idx.IDs = saved.IDs
+	idx.Lengths = saved.Lengths
+	idx.Postings = saved.Postings
+	if idx.Postings == nil {
+		idx.Postings = make(map[string][]Posting)
+	}
+
+	idx.Corpus.Documents = len(idx.IDs)
+	for _, length := range idx.Lengths {
+		idx.Corpus.Length += length
+	}
+
+	for term, postings := range idx.Postings {
+		cf := 0.0
+		for _, p := range postings {
+			cf += p.Count
+		}
+
+		idx.Corpus.DocumentFrequency.Set(term, float64(len(postings)))
+		idx.CollectionFrequency.Set(term, cf)
+	}
+
+	return idx, nil
+}
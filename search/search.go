@@ -0,0 +1,108 @@
+package search
+
+import counter "gnlp/counter"
+import "gnlp/tfidf"
+import "container/heap"
+import "math"
+
+// Scorers rank documents against a query. The score of a document is
+// the sum of Term over the query terms it contains, plus Document.
+type Scorer interface {
+	// The contribution of a query term occurring in a document
+	Term(idx *Index, term string, p Posting) float64
+	// A per-document adjustment, given the total count of the query's
+	// terms
+	Document(idx *Index, doc int, queryLength float64) float64
+}
+
+// Okapi BM25
+type BM25 tfidf.BM25Options
+
+func (s BM25) Term(idx *Index, term string, p Posting) float64 {
+	return idx.Corpus.BM25Weight(term, p.Count, idx.Lengths[p.Doc], tfidf.BM25Options(s))
+}
+
+func (s BM25) Document(idx *Index, doc int, queryLength float64) float64 {
+	return 0.0
+}
+
+// Query likelihood under a unigram language model of each document,
+// Dirichlet-smoothed towards the collection with prior strength Mu.
+// Scores are rank-equivalent to ln P(query | document).
+type Dirichlet struct {
+	Mu float64
+}
+
+func (s Dirichlet) Term(idx *Index, term string, p Posting) float64 {
+	background := idx.CollectionFrequency.Get(term) / idx.Corpus.Length
+
+	return math.Log(1.0 + p.Count/(s.Mu*background))
+}
+
+func (s Dirichlet) Document(idx *Index, doc int, queryLength float64) float64 {
+	return queryLength * math.Log(s.Mu/(idx.Lengths[doc]+s.Mu))
+}
+
+// A scored document
+type Result struct {
+	ID    string
+	Doc   int
+	Score float64
+}
+
+// A min-heap of results, so that the worst of the current top k is
+// the one dropped
+type results []Result
+
+func (r results) Len() int { return len(r) }
+func (r results) Less(i, j int) bool {
+	if r[i].Score == r[j].Score {
+		return r[i].Doc > r[j].Doc
+	}
+	return r[i].Score < r[j].Score
+}
+func (r results) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+
+func (r *results) Push(x interface{}) {
+	*r = append(*r, x.(Result))
+}
+
+func (r *results) Pop() interface{} {
+	old := *r
+	result := old[len(old)-1]
+	*r = old[:len(old)-1]
+
+	return result
+}
+
+// The k best scoring documents containing at least one query term
+// (term -> count), best first
+func (idx *Index) Search(query *counter.Counter, k int, s Scorer) []Result {
+	scores := make(map[int]float64)
+	queryLength := 0.0
+
+	for _, term := range query.Keys() {
+		weight := query.Get(term)
+		queryLength += weight
+
+		for _, p := range idx.Postings[term] {
+			scores[p.Doc] += weight * s.Term(idx, term, p)
+		}
+	}
+
+	top := &results{}
+	for doc, score := range scores {
+		heap.Push(top, Result{ID: idx.IDs[doc], Doc: doc, Score: score + s.Document(idx, doc, queryLength)})
+
+		if top.Len() > k {
+			heap.Pop(top)
+		}
+	}
+
+	best := make([]Result, top.Len())
+	for i := len(best) - 1; i >= 0; i-- {
+		best[i] = heap.Pop(top).(Result)
+	}
+
+	return best
+}
@@ -0,0 +1,78 @@
+package search
+
+import "bytes"
+import counter "gnlp/counter"
+import "gnlp/tfidf"
+import "strings"
+import "testing"
+
+func bag(text string) *counter.Counter {
+	c := counter.New(0.0)
+	for _, w := range strings.Fields(text) {
+		c.Incr(w)
+	}
+
+	return c
+}
+
+func index() *Index {
+	idx := New()
+	idx.Add("cats", bag("the cat sat on the mat with another cat"))
+	idx.Add("dogs", bag("the dog chased the ball"))
+	idx.Add("both", bag("a cat and a dog"))
+	idx.Add("none", bag("nothing to see here"))
+
+	return idx
+}
+
+func ids(results []Result) string {
+	s := []string{}
+	for _, r := range results {
+		s = append(s, r.ID)
+	}
+
+	return strings.Join(s, " ")
+}
+
+func TestSearch(t *testing.T) {
+	idx := index()
+
+	for _, s := range []Scorer{BM25(tfidf.StandardBM25), Dirichlet{Mu: 10}} {
+		if r := ids(idx.Search(bag("cat dog"), 10, s)); r != "both cats dogs" && r != "both dogs cats" {
+			t.Errorf("%T ranked %s", s, r)
+		}
+
+		if r := ids(idx.Search(bag("cat"), 1, s)); r != "cats" {
+			t.Errorf("%T top result for cat: %s", s, r)
+		}
+	}
+
+	if r := idx.Search(bag("unicorn"), 10, Dirichlet{Mu: 10}); len(r) != 0 {
+		t.Errorf("Unknown terms matched %v", r)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	idx := index()
+
+	buf := new(bytes.Buffer)
+	if err := idx.Save(buf); err != nil {
+		t.Fatalf("Failed to save index: %v", err)
+	}
+
+	loaded, err := Load(buf)
+	if err != nil {
+		t.Fatalf("Failed to load index: %v", err)
+	}
+
+	query := bag("the cat")
+	for _, s := range []Scorer{BM25(tfidf.StandardBM25), Dirichlet{Mu: 10}} {
+		expected, found := idx.Search(query, 3, s), loaded.Search(query, 3, s)
+
+		for i, r := range expected {
+			if found[i].ID != r.ID || found[i].Score != r.Score {
+				t.Errorf("%T: result %d was %v, expected %v", s, i, found[i], r)
+			}
+		}
+	}
+}
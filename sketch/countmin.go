@@ -0,0 +1,125 @@
+package sketch
+
+import "fmt"
+import "math"
+import "os"
+
+// A Count-Min sketch (Cormode & Muthukrishnan) - approximate counts for
+// an unbounded set of keys in fixed memory. Estimates never undercount,
+// and with a sketch built by NewWithError overcount by at most
+// epsilon * Total with probability 1 - delta. Counts must only ever be
+// incremented.
+type CountMin struct {
+	Width, Depth int
+	// Only raise the counters that need it when incrementing (Estan &
+	// Varghese's conservative update), which greatly reduces
+	// overcounting
+	Conservative bool
+	// The sum of every increment
+	Total float64
+
+	// Depth rows of Width counters
+	table []float64
+}
+
+func New(width, depth int, conservative bool) *CountMin {
+	if width < 1 || depth < 1 {
+		panic("sketch dimensions must be positive")
+	}
+
+	return &CountMin{Width: width, Depth: depth, Conservative: conservative, table: make([]float64, width*depth)}
+}
+
+// A sketch overcounting by at most epsilon * Total with probability
+// 1 - delta
+func NewWithError(epsilon, delta float64, conservative bool) *CountMin {
+	width := int(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1.0 / delta)))
+
+	return New(width, depth, conservative)
+}
+
+// FNV-1a, split into two halves for double hashing
+func hash(k string) (uint32, uint32) {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(k); i++ {
+		h ^= uint64(k[i])
+		h *= 1099511628211
+	}
+
+	return uint32(h), uint32(h>>32) | 1
+}
+
+// The position of k's counter in each row
+func (s *CountMin) cells(k string) []int {
+	h1, h2 := hash(k)
+	result := make([]int, s.Depth)
+
+	for row := range result {
+		result[row] = row*s.Width + int((h1+uint32(row)*h2)%uint32(s.Width))
+	}
+
+	return result
+}
+
+// The smallest of the given counters
+func (s *CountMin) min(cells []int) float64 {
+	min := math.Inf(1)
+	for _, cell := range cells {
+		if s.table[cell] < min {
+			min = s.table[cell]
+		}
+	}
+
+	return min
+}
+
+// The estimated count of a key
+func (s *CountMin) Get(k string) float64 {
+	return s.min(s.cells(k))
+}
+
+// Add v (which mustn't be negative) to a key's count
+func (s *CountMin) IncrBy(k string, v float64) {
+	if v < 0 {
+		panic("count-min sketches can't be decremented")
+	}
+
+	cells := s.cells(k)
+	s.Total += v
+
+	if !s.Conservative {
+		for _, cell := range cells {
+			s.table[cell] += v
+		}
+		return
+	}
+
+	target := s.min(cells) + v
+	for _, cell := range cells {
+		if s.table[cell] < target {
+			s.table[cell] = target
+		}
+	}
+}
+
+// Increment a key's count
+func (s *CountMin) Incr(k string) {
+	s.IncrBy(k, 1.0)
+}
+
+// Add the counts from o (e.g. a sketch of another shard of the corpus)
+// to s. The sketches must have the same dimensions. Estimates from the
+// merged sketch still never undercount.
+func (s *CountMin) Merge(o *CountMin) os.Error {
+	if s.Width != o.Width || s.Depth != o.Depth {
+		return fmt.Errorf("sketch: can't merge a %dx%d sketch into a %dx%d one", o.Depth, o.Width, s.Depth, s.Width)
+	}
+
+	for i, v := range o.table {
+		s.table[i] += v
+	}
+	s.Total += o.Total
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package sketch
+
+import counter "gnlp/counter"
+import "fmt"
+import "rand"
+import "testing"
+
+// A Zipfian stream of keys, with exact counts
+func zipfStream(n int) ([]string, *counter.Counter) {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.2, 1.0, 10000)
+
+	stream := make([]string, n)
+	exact := counter.New(0.0)
+	for i := range stream {
+		stream[i] = fmt.Sprintf("w%d", z.Uint64())
+		exact.Incr(stream[i])
+	}
+
+	return stream, exact
+}
+
+func TestCountMin(t *testing.T) {
+	stream, exact := zipfStream(50000)
+
+	plain, conservative := NewWithError(0.001, 0.01, false), NewWithError(0.001, 0.01, true)
+	for _, k := range stream {
+		plain.Incr(k)
+		conservative.Incr(k)
+	}
+
+	plainError, conservativeError := 0.0, 0.0
+	for _, k := range exact.Keys() {
+		e, p, c := exact.Get(k), plain.Get(k), conservative.Get(k)
+		if p < e || c < e {
+			t.Fatalf("%s undercounted: %f, %f < %f", k, p, c, e)
+		}
+
+		if p-e > 0.001*plain.Total {
+			t.Errorf("%s overcounted by %f", k, p-e)
+		}
+
+		plainError += p - e
+		conservativeError += c - e
+	}
+
+	if conservativeError > plainError {
+		t.Errorf("Conservative update should overcount less (%f vs %f)", conservativeError, plainError)
+	}
+
+	// Sketching two halves and merging them gives the plain sketch
+	a, b := New(plain.Width, plain.Depth, false), New(plain.Width, plain.Depth, false)
+	for i, k := range stream {
+		if i%2 == 0 {
+			a.Incr(k)
+		} else {
+			b.Incr(k)
+		}
+	}
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, k := range exact.Keys() {
+		if a.Get(k) != plain.Get(k) {
+			t.Fatalf("Merged sketch differs for %s", k)
+		}
+	}
+
+	if a.Merge(New(10, 2, false)) == nil {
+		t.Errorf("Merging sketches of different sizes should fail")
+	}
+}
+
+func TestSpaceSaving(t *testing.T) {
+	stream, exact := zipfStream(50000)
+
+	s := NewSpaceSaving(100)
+	for _, k := range stream {
+		s.Incr(k)
+	}
+
+	if len(s.Keys()) != 100 || s.Total != 50000 {
+		t.Fatalf("Tracking %d keys, total %f", len(s.Keys()), s.Total)
+	}
+
+	for _, k := range s.Keys() {
+		if e := exact.Get(k); s.Get(k) < e || s.Get(k)-s.Error(k) > e {
+			t.Errorf("%s: estimate %f (error %f) inconsistent with %f", k, s.Get(k), s.Error(k), e)
+		}
+	}
+
+	// Heavy hitters are always tracked
+	for _, k := range exact.Keys() {
+		if exact.Get(k) > s.Total/100 && s.Get(k) == 0 {
+			t.Errorf("Heavy hitter %s isn't tracked", k)
+		}
+	}
+
+	want, got := exact.TopK(5), s.Top(5)
+	for i := range want {
+		if want[i] != got[i] {
+			t.Errorf("Top 5 = %v, expected %v", got, want)
+			break
+		}
+	}
+}
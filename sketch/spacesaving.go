@@ -0,0 +1,150 @@
+package sketch
+
+import "gnlp"
+import counter "gnlp/counter"
+import "container/heap"
+import "sort"
+
+type entry struct {
+	key        string
+	count, err float64
+	// position in the heap
+	index int
+}
+
+// A min-heap of entries by count
+type entries []*entry
+
+func (e entries) Len() int           { return len(e) }
+func (e entries) Less(i, j int) bool { return e[i].count < e[j].count }
+func (e entries) Swap(i, j int) {
+	e[i], e[j] = e[j], e[i]
+	e[i].index = i
+	e[j].index = j
+}
+
+func (e *entries) Push(x interface{}) {
+	item := x.(*entry)
+	item.index = len(*e)
+	*e = append(*e, item)
+}
+
+func (e *entries) Pop() interface{} {
+	old := *e
+	item := old[len(old)-1]
+	*e = old[:len(old)-1]
+
+	return item
+}
+
+// Restore the heap order after the count of the entry at i grows
+func (e *entries) fix(i int) {
+	heap.Push(e, heap.Remove(e, i))
+}
+
+// Tracks the most frequent keys of a stream in the space of Capacity
+// counters, using Metwally et al.'s Space-Saving algorithm. Any key
+// occurring more than Total / Capacity times is guaranteed to be
+// tracked. A newly tracked key inherits the count of the key it
+// evicts, so counts are overestimates by at most Error(key).
+type SpaceSaving struct {
+	Capacity int
+	// The sum of every increment
+	Total float64
+
+	entries entries
+	keys    map[string]*entry
+}
+
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	if capacity < 1 {
+		panic("space-saving capacity must be positive")
+	}
+
+	return &SpaceSaving{Capacity: capacity, keys: make(map[string]*entry)}
+}
+
+// Add v (which mustn't be negative) to a key's count
+func (s *SpaceSaving) IncrBy(k string, v float64) {
+	if v < 0 {
+		panic("space-saving counts can't be decremented")
+	}
+
+	s.Total += v
+
+	if e, ok := s.keys[k]; ok {
+		e.count += v
+		s.entries.fix(e.index)
+		return
+	}
+
+	if len(s.entries) < s.Capacity {
+		e := &entry{key: k, count: v}
+		heap.Push(&s.entries, e)
+		s.keys[k] = e
+		return
+	}
+
+	// Replace the least frequent key
+	e := s.entries[0]
+	s.keys[e.key] = nil, false
+
+	e.key, e.err = k, e.count
+	e.count += v
+	s.keys[k] = e
+	s.entries.fix(0)
+}
+
+// Increment a key's count
+func (s *SpaceSaving) Incr(k string) {
+	s.IncrBy(k, 1.0)
+}
+
+// The estimated count of a key, or 0 if it isn't tracked
+func (s *SpaceSaving) Get(k string) float64 {
+	if e, ok := s.keys[k]; ok {
+		return e.count
+	}
+
+	return 0.0
+}
+
+// The most a tracked key's count can be overestimated by
+func (s *SpaceSaving) Error(k string) float64 {
+	if e, ok := s.keys[k]; ok {
+		return e.err
+	}
+
+	return 0.0
+}
+
+// The (at most) n tracked keys with the largest counts, largest first
+// (ties broken by key)
+func (s *SpaceSaving) Top(n int) []string {
+	return s.Counter().TopK(n)
+}
+
+// The tracked keys and their estimated counts, e.g. to pick features
+// before counting them exactly
+func (s *SpaceSaving) Counter() *counter.Counter {
+	result := counter.New(0.0)
+	for _, e := range s.entries {
+		result.Set(e.key, e.count)
+	}
+
+	return result
+}
+
+// The tracked keys in sorted order
+func (s *SpaceSaving) Keys() []string {
+	result := make([]string, 0, len(s.entries))
+	for _, e := range s.entries {
+		result = append(result, e.key)
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+var _ gnlp.Tally = New(1, 1, false)
+var _ gnlp.Tally = NewSpaceSaving(1)
@@ -1,6 +1,6 @@
 #!/bin/bash
 
-FOLDERS="gnlp counter frozencounter smoothing features minimizer"
+FOLDERS="gnlp counter frozencounter smoothing features ngram tfidf search sketch minimizer"
 
 for folder in $FOLDERS; do
 	pushd $folder > /dev/null
@@ -0,0 +1,185 @@
+package tfidf
+
+import counter "gnlp/counter"
+import "math"
+
+// Ways of turning a raw term count into a term frequency
+type TFVariant int
+
+const (
+	// The raw count
+	RawTF TFVariant = iota
+	// 1 + ln(count)
+	LogTF
+	// 0.5 + 0.5 * count / (largest count in the document)
+	AugmentedTF
+)
+
+type Options struct {
+	TF TFVariant
+	// Use ln((1 + N) / (1 + df)) + 1 rather than ln(N / df), so that
+	// unseen terms don't divide by zero and terms in every document
+	// aren't zeroed out
+	SmoothIDF bool
+}
+
+var Standard = Options{TF: LogTF, SmoothIDF: true}
+
+// BM25 parameters - K1 controls term frequency saturation and B the
+// strength of document length normalization
+type BM25Options struct {
+	K1, B float64
+}
+
+var StandardBM25 = BM25Options{K1: 1.2, B: 0.75}
+
+// Document frequencies accumulated over a corpus of bag-of-words
+// documents
+type Corpus struct {
+	DocumentFrequency *counter.Counter
+	Documents         int
+	// The total number of tokens over all documents
+	Length float64
+}
+
+func New() *Corpus {
+	return &Corpus{DocumentFrequency: counter.New(0.0)}
+}
+
+// Add a document (term -> count) to the corpus
+func (c *Corpus) Add(doc *counter.Counter) {
+	for _, term := range doc.Keys() {
+		c.DocumentFrequency.Incr(term)
+	}
+
+	c.Documents += 1
+	c.Length += doc.Sum() - doc.Base
+}
+
+// The average document length
+func (c *Corpus) AverageLength() float64 {
+	if c.Documents == 0 {
+		return 0.0
+	}
+
+	return c.Length / float64(c.Documents)
+}
+
+// The inverse document frequency of term
+func (c *Corpus) IDF(term string, smooth bool) float64 {
+	df := c.DocumentFrequency.Get(term)
+	n := float64(c.Documents)
+
+	if smooth {
+		return math.Log((1.0+n)/(1.0+df)) + 1.0
+	}
+
+	return math.Log(n / df)
+}
+
+// Convert a document's term counts into term frequencies
+func TF(doc *counter.Counter, variant TFVariant) *counter.Counter {
+	result := doc.Copy()
+
+	switch variant {
+	case LogTF:
+		result.Apply(func(term *string, count float64) float64 {
+			if count <= 0 {
+				return 0.0
+			}
+			return 1.0 + math.Log(count)
+		})
+	case AugmentedTF:
+		max := 0.0
+		for _, term := range doc.Keys() {
+			max = math.Max(max, doc.Get(term))
+		}
+
+		result.Apply(func(term *string, count float64) float64 {
+			if term == nil {
+				return 0.0
+			}
+			return 0.5 + 0.5*count/max
+		})
+	}
+
+	return result
+}
+
+// Weight a document's term counts by TF-IDF
+func (c *Corpus) TFIDF(doc *counter.Counter, opt Options) *counter.Counter {
+	result := TF(doc, opt.TF)
+	result.Base = 0.0
+
+	result.Apply(func(term *string, tf float64) float64 {
+		if term == nil {
+			return 0.0
+		}
+		return tf * c.IDF(*term, opt.SmoothIDF)
+	})
+
+	return result
+}
+
+// The BM25 IDF of a term, ln(1 + (N - df + 0.5) / (df + 0.5)), which
+// unlike the classic form is never negative
+func (c *Corpus) BM25IDF(term string) float64 {
+	df := c.DocumentFrequency.Get(term)
+	n := float64(c.Documents)
+
+	return math.Log(1.0 + (n-df+0.5)/(df+0.5))
+}
+
+// The BM25 score of a single term for a document
+func (c *Corpus) BM25(doc *counter.Counter, term string, opt BM25Options) float64 {
+	return c.BM25Weight(term, doc.Get(term), doc.Sum()-doc.Base, opt)
+}
+
+// The BM25 score of a term occurring tf times in a document of the
+// given length
+func (c *Corpus) BM25Weight(term string, tf, length float64, opt BM25Options) float64 {
+	if tf <= 0 {
+		return 0.0
+	}
+
+	norm := 1.0 - opt.B
+	if avg := c.AverageLength(); avg > 0 {
+		norm += opt.B * length / avg
+	}
+
+	return c.BM25IDF(term) * tf * (opt.K1 + 1.0) / (tf + opt.K1*norm)
+}
+
+// The BM25 score of a query (a bag of terms) for a document
+func (c *Corpus) BM25Score(doc, query *counter.Counter, opt BM25Options) float64 {
+	score := 0.0
+
+	for _, term := range query.Keys() {
+		score += query.Get(term) * c.BM25(doc, term, opt)
+	}
+
+	return score
+}
+
+// The cosine similarity of two vectors (e.g. TF-IDF weighted
+// documents), both with a default of 0
+func Cosine(a, b *counter.Counter) float64 {
+	dot, normA, normB := 0.0, 0.0, 0.0
+
+	for _, term := range a.Keys() {
+		va := a.Get(term)
+		dot += va * b.Get(term)
+		normA += va * va
+	}
+
+	for _, term := range b.Keys() {
+		vb := b.Get(term)
+		normB += vb * vb
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0.0
+	}
+
+	return dot / math.Sqrt(normA*normB)
+}
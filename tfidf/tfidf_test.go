@@ -0,0 +1,75 @@
+package tfidf
+
+import counter "gnlp/counter"
+import "math"
+import "strings"
+import "testing"
+
+func bag(text string) *counter.Counter {
+	c := counter.New(0.0)
+	for _, w := range strings.Fields(text) {
+		c.Incr(w)
+	}
+
+	return c
+}
+
+var docs = []*counter.Counter{
+	bag("the cat sat on the mat"),
+	bag("the dog sat"),
+	bag("a cat and a dog"),
+}
+
+func corpus() *Corpus {
+	c := New()
+	for _, doc := range docs {
+		c.Add(doc)
+	}
+
+	return c
+}
+
+func TestTFIDF(t *testing.T) {
+	c := corpus()
+
+	weights := c.TFIDF(docs[0], Options{TF: RawTF})
+	if w := weights.Get("the"); math.Abs(w-2*math.Log(1.5)) > 1e-9 {
+		t.Errorf("TF-IDF(the) = %f, expected %f", w, 2*math.Log(1.5))
+	}
+	if w := weights.Get("mat"); math.Abs(w-math.Log(3)) > 1e-9 {
+		t.Errorf("TF-IDF(mat) = %f, expected %f", w, math.Log(3))
+	}
+
+	augmented := TF(docs[0], AugmentedTF)
+	if tf := augmented.Get("cat"); tf != 0.75 {
+		t.Errorf("Augmented TF(cat) = %f, expected 0.75", tf)
+	}
+
+	if w := c.TFIDF(docs[0], Standard).Get("dog"); w != 0.0 {
+		t.Errorf("TF-IDF of a missing term = %f", w)
+	}
+}
+
+func TestBM25(t *testing.T) {
+	c := corpus()
+	query := bag("cat dog")
+
+	short, long := c.BM25Score(docs[1], bag("dog"), StandardBM25), c.BM25Score(docs[2], bag("dog"), StandardBM25)
+	if short <= long {
+		t.Errorf("Shorter documents should score higher (%f vs %f)", short, long)
+	}
+
+	if both, one := c.BM25Score(docs[2], query, StandardBM25), c.BM25Score(docs[0], query, StandardBM25); both <= one {
+		t.Errorf("Matching both terms should score higher (%f vs %f)", both, one)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	if s := Cosine(docs[1], docs[1]); math.Abs(s-1.0) > 1e-9 {
+		t.Errorf("Self-similarity = %f", s)
+	}
+
+	if s := Cosine(bag("a b"), bag("c d")); s != 0.0 {
+		t.Errorf("Disjoint similarity = %f", s)
+	}
+}